@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+// Building heights the benchmarks compare: 64 is the bitmask car's limit,
+// 200 needs four words in the multiword car.
+var benchFloors = []int{10, 50, 64, 200}
+
+// benchCar is one implementation built over a 1..floors building.
+type benchCar struct {
+	name string
+	new  func(id int) CarModel
+}
+
+// benchCars returns every implementation that fits floors, in a fixed order
+// so benchmark output lines up between runs.
+func benchCars(floors int) []benchCar {
+	var cars []benchCar
+	for _, name := range slices.Sorted(maps.Keys(carConstructors)) {
+		if name == "bitmask" && floors > bitmaskMaxFloors {
+			continue
+		}
+		newCar := carConstructors[name]
+		cars = append(cars, benchCar{name, func(id int) CarModel { return newCar(id, 1, floors) }})
+	}
+	return cars
+}
+
+// runBench runs fn as a sub-benchmark per building height and implementation,
+// named floors=N/impl so benchstat can group either way.
+func runBench(b *testing.B, fn func(b *testing.B, floors int, car benchCar)) {
+	for _, floors := range benchFloors {
+		for _, car := range benchCars(floors) {
+			b.Run(fmt.Sprintf("floors=%d/%s", floors, car.name), func(b *testing.B) {
+				b.ReportAllocs()
+				fn(b, floors, car)
+			})
+		}
+	}
+}
+
+// stopScanner is the unexported half of the LOOK loop every car implements.
+type stopScanner interface {
+	hasStopsAbove() bool
+}
+
+func BenchmarkAddRequest(b *testing.B) {
+	runBench(b, func(b *testing.B, floors int, bc benchCar) {
+		reqs := NewTrafficGenerator(TrafficInterFloor, 1, floors, 1).Requests(1024)
+		car := bc.new(1)
+		i := 0
+		for b.Loop() {
+			car.AddRequest(reqs[i%len(reqs)])
+			i++
+		}
+	})
+}
+
+func BenchmarkStep(b *testing.B) {
+	runBench(b, func(b *testing.B, floors int, bc benchCar) {
+		// A new hall call every 8 steps keeps the car busy without
+		// saturating every floor.
+		reqs := NewTrafficGenerator(TrafficInterFloor, 1, floors, 1).Requests(1024)
+		car := bc.new(1)
+		i := 0
+		for b.Loop() {
+			if i%8 == 0 {
+				car.AddRequest(reqs[i/8%len(reqs)])
+			}
+			car.Step()
+			i++
+		}
+	})
+}
+
+func BenchmarkPendingCount(b *testing.B) {
+	runBench(b, func(b *testing.B, floors int, bc benchCar) {
+		car := bc.new(1)
+		for _, r := range NewTrafficGenerator(TrafficInterFloor, 1, floors, 1).Requests(floors / 4) {
+			car.AddRequest(r)
+		}
+		for b.Loop() {
+			car.PendingCount()
+		}
+	})
+}
+
+func BenchmarkHasStopsAbove(b *testing.B) {
+	runBench(b, func(b *testing.B, floors int, bc benchCar) {
+		// A lone stop at the top floor seen from the lobby: the multiword car
+		// has to walk every word to find it.
+		car := bc.new(1)
+		car.AddRequest(Request{Floor: floors, Type: CabCall})
+		s := car.(stopScanner)
+		for b.Loop() {
+			s.hasStopsAbove()
+		}
+	})
+}
+
+// benchDay is an office day of arrivals for a 1..floors building: an
+// up-peak hour, inter-floor traffic, a lunch hour, more inter-floor traffic
+// and a down-peak hour, nine hours in all.
+func benchDay(floors int) []Arrival {
+	periods := []struct {
+		profile   TrafficProfile
+		window    time.Duration
+		perMinute float64
+	}{
+		{TrafficUpPeak, time.Hour, 8},
+		{TrafficInterFloor, 3 * time.Hour, 2},
+		{TrafficLunch, time.Hour, 8},
+		{TrafficInterFloor, 3 * time.Hour, 2},
+		{TrafficDownPeak, time.Hour, 8},
+	}
+	var day []Arrival
+	var start time.Duration
+	for i, p := range periods {
+		for _, a := range NewTrafficGenerator(p.profile, 1, floors, uint64(i+1)).Generate(p.window, p.perMinute) {
+			a.At += start
+			day = append(day, a)
+		}
+		start += p.window
+	}
+	return day
+}
+
+func BenchmarkSimulatedDay(b *testing.B) {
+	runBench(b, func(b *testing.B, floors int, bc benchCar) {
+		day := benchDay(floors)
+		for b.Loop() {
+			cars := make([]CarModel, 4)
+			for i := range cars {
+				cars[i] = bc.new(i + 1)
+			}
+			s := NewSimulator(NewDispatcherWithCars(1, floors, cars), time.Second)
+			s.AddArrivals(day)
+			s.Run()
+		}
+	})
+}
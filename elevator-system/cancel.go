@@ -0,0 +1,42 @@
+package main
+
+import "slices"
+
+// cancellable reports whether the stop r set may be cancelled: no rider on
+// board is bound for a cab stop, and nobody is waiting at a hall stop.
+func (c *cabin) cancellable(r Request) bool {
+	if r.Type == CabCall {
+		return !slices.ContainsFunc(c.occupants, func(p *Passenger) bool { return p.Destination == r.Floor })
+	}
+	return !slices.ContainsFunc(c.waiting, func(p *Passenger) bool {
+		return p.Origin == r.Floor && p.Direction() == r.Direction
+	})
+}
+
+// SetAntiNuisance turns anti-nuisance cab-call cancellation on or off (default
+// off). With it on, a car whose door closes at a terminal floor with nobody on
+// board cancels its remaining cab calls: nobody is left to have pressed them.
+// The load is only known when the car carries Passengers; a car fed plain
+// requests always looks empty and should leave it off.
+func (c *cabin) SetAntiNuisance(on bool) { c.antiNuisance = on }
+
+// AntiNuisance reports whether anti-nuisance cancellation is on.
+func (c *cabin) AntiNuisance() bool { return c.antiNuisance }
+
+// cancelCabCalls cancels every cab call of car.
+func cancelCabCalls(car CarModel) {
+	up, down := car.StopsCabSnapshot()
+	for _, f := range append(up, down...) {
+		car.CancelRequest(Request{Floor: f, Type: CabCall})
+	}
+}
+
+// WithAntiNuisance turns on anti-nuisance cab-call cancellation in every car
+// (see SetAntiNuisance).
+func WithAntiNuisance() DispatcherOption {
+	return func(d *Dispatcher) {
+		for _, car := range d.Elevators {
+			car.SetAntiNuisance(true)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCancelRequest_CabCall(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 5, Type: CabCall})
+			car.AddRequest(Request{Floor: 8, Type: CabCall})
+			car.Step()
+			car.Step() // at 3
+
+			if !car.CancelRequest(Request{Floor: 8, Type: CabCall}) {
+				t.Fatal("expected the stop at 8 cancelled")
+			}
+			if car.CancelRequest(Request{Floor: 8, Type: CabCall}) {
+				t.Error("expected a second cancel to find nothing")
+			}
+			if n := car.PendingCount(); n != 1 {
+				t.Errorf("expected 1 stop left, got %d", n)
+			}
+			runCarUntilIdle(car, 20)
+			if car.Floor() != 5 {
+				t.Errorf("expected the car to end at 5, got %d", car.Floor())
+			}
+		})
+	}
+}
+
+func TestCancelRequest_LastStopHaltsCar(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 7, Type: CabCall})
+			car.Step()
+			car.Step() // at 3
+
+			car.CancelRequest(Request{Floor: 7, Type: CabCall})
+			if car.HasPendingRequests() || car.CurrentState() != StateIdle || car.CurrentDirection() != DirIdle {
+				t.Errorf("expected the car idle with no stops, got %s %s (%d stops)",
+					car.CurrentState(), car.CurrentDirection(), car.PendingCount())
+			}
+			car.Step()
+			if car.Floor() != 3 {
+				t.Errorf("expected the car to stay at 3, got %d", car.Floor())
+			}
+		})
+	}
+}
+
+func TestCancelRequest_RecalcsBounds(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.AddRequest(Request{Floor: 4, Type: CabCall})
+	e.AddRequest(Request{Floor: 9, Direction: DirDown, Type: HallCall})
+	e.CancelRequest(Request{Floor: 9, Direction: DirDown, Type: HallCall})
+	if e.minRequest != 4 || e.maxRequest != 4 {
+		t.Errorf("expected bounds 4..4, got %d..%d", e.minRequest, e.maxRequest)
+	}
+	e.CancelRequest(Request{Floor: 4, Type: CabCall})
+	if e.minRequest <= e.maxRequest {
+		t.Errorf("expected empty bounds, got %d..%d", e.minRequest, e.maxRequest)
+	}
+}
+
+func TestCancelRequest_KeepsStopsPassengersNeed(t *testing.T) {
+	car := NewElevator(1, 1, 10)
+	car.AddPassenger(NewPassenger(1, 1, 6, 0)) // boards at once
+	car.AddPassenger(NewPassenger(2, 4, 9, 0))
+	car.AddRequest(Request{Floor: 7, Direction: DirDown, Type: HallCall})
+
+	if car.CancelRequest(Request{Floor: 6, Type: CabCall}) {
+		t.Error("expected the rider's stop kept")
+	}
+	if car.CancelRequest(Request{Floor: 4, Direction: DirUp, Type: HallCall}) {
+		t.Error("expected the waiting passenger's stop kept")
+	}
+	if !car.CancelRequest(Request{Floor: 7, Direction: DirDown, Type: HallCall}) {
+		t.Error("expected the unclaimed hall stop cancelled")
+	}
+	if car.CancelRequest(Request{Floor: 11, Type: CabCall}) {
+		t.Error("expected a floor out of range ignored")
+	}
+}
+
+func TestCancelRequest_DropsRegisteredHallCall(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	r := Request{Floor: 6, Direction: DirDown, Type: HallCall}
+	car := d.Dispatch(r)
+	car.CancelRequest(r)
+	if calls := d.OutstandingCalls(); len(calls) != 0 {
+		t.Errorf("expected the cancelled call gone from the registry, got %v", calls)
+	}
+}
+
+func TestCancelRequest_VIPTargetLeavesNextVIPStopHere(t *testing.T) {
+	e := NewElevator(1, 1, 30)
+	e.AddRequest(Request{Floor: 26, Type: CabCall, Priority: PriorityVIP})
+	e.AddRequest(Request{Floor: 8, Type: CabCall, Priority: PriorityVIP})
+	for e.CurrentFloor < 8 {
+		e.Step() // express to 26: runs through 8
+	}
+	e.AddRequest(Request{Floor: 7, Direction: DirDown, Type: HallCall})
+
+	e.CancelRequest(Request{Floor: 26, Type: CabCall})
+	if e.State != StateDoorOpen || e.CurrentFloor != 8 {
+		t.Fatalf("expected the VIP stop at 8 served at once, got %s at %d", e.State, e.CurrentFloor)
+	}
+	if stops := runUntilIdle(e, 20); !intSliceEqual(stops, []int{7}) || e.HasVIPRequests() {
+		t.Errorf("expected the car on to 7 with no VIP stops left, got stops %v", stops)
+	}
+}
+
+func TestPressCabButton_SecondPressCancels(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.PressCabButton(6)
+			car.PressCabButton(8)
+			car.PressCabButton(8) // mis-press undone
+			up, _ := car.StopsCabSnapshot()
+			if !intSliceEqual(up, []int{6}) {
+				t.Errorf("expected only 6 lit, got %v", up)
+			}
+			if err := car.PressCabButton(11); !errors.Is(err, ErrFloorNotServed) {
+				t.Errorf("expected ErrFloorNotServed, got %v", err)
+			}
+		})
+	}
+}
+
+// prankRide carries a rider from 1 to 10 who presses 4 on the way up and
+// returns the car once it settles.
+func prankRide(car CarModel) CarModel {
+	car.AddPassenger(NewPassenger(1, 1, 10, 0))
+	for car.Floor() < 6 {
+		car.Step()
+	}
+	car.PressCabButton(4)
+	runCarUntilIdle(car, 40)
+	return car
+}
+
+func TestAntiNuisance_CancelsCabCallsAtTerminalWhenEmpty(t *testing.T) {
+	if car := prankRide(NewDispatcher(1, 1, 10).Elevators[0]); car.Floor() != 4 {
+		t.Fatalf("expected the prank call served by default, car at %d", car.Floor())
+	}
+
+	car := NewDispatcher(1, 1, 10, WithAntiNuisance()).Elevators[0]
+	if prankRide(car); car.Floor() != 10 || car.HasPendingRequests() {
+		t.Errorf("expected the prank call cancelled at 10, car at %d with %d stops",
+			car.Floor(), car.PendingCount())
+	}
+}
+
+func TestAntiNuisance_KeepsCallsAwayFromTerminals(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.SetAntiNuisance(true)
+	e.AddPassenger(NewPassenger(1, 1, 7, 0))
+	for e.CurrentFloor < 5 {
+		e.Step()
+	}
+	e.PressCabButton(3)
+	runCarUntilIdle(e, 40)
+	if e.CurrentFloor != 3 {
+		t.Errorf("expected the call to 3 served after emptying at 7, car at %d", e.CurrentFloor)
+	}
+}
+
+func TestAntiNuisance_ZoneTerminal(t *testing.T) {
+	e := NewElevator(1, 1, 20)
+	e.SetServedFloors(1, 11, 12, 13, 14, 15)
+	e.SetAntiNuisance(true)
+	e.AddPassenger(NewPassenger(1, 1, 15, 0))
+	for e.CurrentFloor < 13 {
+		e.Step()
+	}
+	e.PressCabButton(12)
+	runCarUntilIdle(e, 40)
+	if e.CurrentFloor != 15 {
+		t.Errorf("expected the call cancelled at the zone's top floor 15, car at %d", e.CurrentFloor)
+	}
+}
+
+func TestWithAntiNuisance(t *testing.T) {
+	d := NewDispatcher(3, 1, 10, WithAntiNuisance())
+	for _, car := range d.Elevators {
+		if !car.AntiNuisance() {
+			t.Errorf("car %d: expected anti-nuisance on", car.CarID())
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+// Capacity is a car's rated load: a weight limit and a headcount limit.
+// The car is full at either limit, and overloaded past it.
+type Capacity struct {
+	MaxWeight     int // sum of passenger weights
+	MaxPassengers int // 0: no headcount limit
+
+	// NearFull is the share of either limit at which Dispatcher stops giving
+	// the car new hall calls (0: never).
+	NearFull float64
+}
+
+// DefaultCapacity fits ten passengers of default weight.
+func DefaultCapacity() Capacity {
+	return Capacity{
+		MaxWeight:     10 * passengerWeight,
+		MaxPassengers: 10,
+		NearFull:      0.8,
+	}
+}
+
+// Capacity returns the car's rated load.
+func (c *cabin) Capacity() Capacity { return c.capacity }
+
+// SetCapacity replaces the car's rated load, e.g. for a service car.
+func (c *cabin) SetCapacity(cp Capacity) { c.capacity = cp }
+
+// WeightSensor reports whether the car is full by weight or headcount.
+// A full car skips hall stops but still serves its riders.
+func (c *cabin) WeightSensor() bool {
+	cp := c.capacity
+	return c.currentWeight >= cp.MaxWeight ||
+		(cp.MaxPassengers > 0 && len(c.occupants) >= cp.MaxPassengers)
+}
+
+// NearFull reports whether the car's load has reached the NearFull share of
+// either limit.
+func (c *cabin) NearFull() bool {
+	cp := c.capacity
+	if cp.NearFull <= 0 {
+		return false
+	}
+	return float64(c.currentWeight) >= cp.NearFull*float64(cp.MaxWeight) ||
+		(cp.MaxPassengers > 0 && float64(len(c.occupants)) >= cp.NearFull*float64(cp.MaxPassengers))
+}
+
+// overloaded reports whether the car is past either limit: it sounds the
+// alarm and will not leave until enough riders step off.
+func (c *cabin) overloaded() bool {
+	cp := c.capacity
+	return c.currentWeight > cp.MaxWeight ||
+		(cp.MaxPassengers > 0 && len(c.occupants) > cp.MaxPassengers)
+}
+
+// fits reports whether p could ride in the car on its own. A passenger too
+// heavy for the empty car is never boarded, so the alarm can always clear.
+func (c *cabin) fits(p *Passenger) bool {
+	return p.Weight <= c.capacity.MaxWeight
+}
+
+// stepOff puts the last passenger who boarded at floor back among the
+// waiting and returns it, or nil if nobody boarded there. Riders who got on
+// earlier are never put out at a floor they did not ask for.
+func (c *cabin) stepOff(floor int) *Passenger {
+	for i := len(c.occupants) - 1; i >= 0; i-- {
+		p := c.occupants[i]
+		if p.Origin != floor {
+			continue
+		}
+		c.occupants = append(c.occupants[:i], c.occupants[i+1:]...)
+		c.currentWeight -= p.Weight
+		c.await(p)
+		return p
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCapacity_HeadcountOverloadHoldsDoor(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.SetCapacity(Capacity{MaxWeight: 100, MaxPassengers: 2})
+			ps := []*Passenger{NewPassenger(1, 1, 6, 0), NewPassenger(2, 1, 6, 0), NewPassenger(3, 1, 6, 0)}
+			for _, p := range ps {
+				car.AddPassenger(p) // door open at floor 1: boards at once
+			}
+			if car.CurrentState() != StateOverloaded {
+				t.Fatalf("expected overload alarm with 3 riders, got %s", car.CurrentState())
+			}
+
+			if evs, _ := stepBody(car.StepEvents()); evs[0].Kind != StepOverloadAlarm {
+				t.Errorf("expected StepOverloadAlarm, got %v", evs[0].Kind)
+			}
+			if ps[2].State != PassengerWaiting || len(car.Occupants()) != 2 {
+				t.Fatalf("expected the last to board to step off, got %s with %d riders", ps[2].State, len(car.Occupants()))
+			}
+			if car.CurrentState() != StateDoorOpen || car.Floor() != 1 {
+				t.Errorf("expected door re-timed at floor 1, got %s at %d", car.CurrentState(), car.Floor())
+			}
+			if up, _ := car.StopsHallSnapshot(); !intSliceEqual(up, []int{1}) {
+				t.Errorf("expected hall call re-registered at 1, got %v", up)
+			}
+
+			runCarUntilIdle(car, 100)
+			for _, p := range ps {
+				if p.State != PassengerArrived {
+					t.Errorf("passenger %d: expected arrived on a later trip, got %s", p.ID, p.State)
+				}
+			}
+		})
+	}
+}
+
+func TestCapacity_WeightOverloadSheds(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	a := &Passenger{ID: 1, Origin: 1, Destination: 5, Weight: 70}
+	b := &Passenger{ID: 2, Origin: 1, Destination: 3, Weight: 45}
+	e.AddPassenger(a)
+	e.AddPassenger(b) // 115 > 100
+
+	for e.State == StateOverloaded {
+		e.Step()
+		if e.CurrentFloor != 1 {
+			t.Fatal("overloaded car left the floor")
+		}
+	}
+	if e.currentWeight != 70 || b.State != PassengerWaiting {
+		t.Errorf("expected b to step off leaving 70, got %d (b %s)", e.currentWeight, b.State)
+	}
+}
+
+func TestCapacity_BoardingStopsAtFirstPassengerOver(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.SetCapacity(Capacity{MaxWeight: 1000, MaxPassengers: 3})
+	var ps []*Passenger
+	for i := range 8 {
+		p := NewPassenger(i+1, 4, 8, 0)
+		ps = append(ps, p)
+		e.AddPassenger(p)
+	}
+	for e.State != StateOverloaded {
+		e.Step()
+	}
+	if n := len(e.Occupants()); n != 4 {
+		t.Fatalf("expected 3 riders plus the one who set off the alarm, got %d", n)
+	}
+	if n := len(e.Waiting()); n != 4 {
+		t.Errorf("expected the other 4 left waiting, got %d", n)
+	}
+
+	alarms := 0
+	for e.State == StateOverloaded {
+		e.Step()
+		alarms++
+	}
+	if alarms != 1 || len(e.Occupants()) != 3 {
+		t.Errorf("expected one step off clearing the alarm, got %d alarm steps and %d riders", alarms, len(e.Occupants()))
+	}
+
+	runUntilIdle(e, 200)
+	for _, p := range ps {
+		if p.State != PassengerArrived {
+			t.Errorf("passenger %d: expected arrived on a later trip, got %s", p.ID, p.State)
+		}
+	}
+}
+
+func TestCapacity_TooHeavyForEmptyCarNeverBoards(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	p := &Passenger{ID: 1, Origin: 4, Destination: 8, Weight: 150}
+	e.AddPassenger(p)
+
+	runUntilIdle(e, 100)
+	if p.State != PassengerWaiting || len(e.Occupants()) != 0 {
+		t.Errorf("expected passenger left waiting, got %s", p.State)
+	}
+	if e.HasPendingRequests() {
+		t.Error("expected the car to drop the hall stop and go idle")
+	}
+}
+
+func TestCapacity_RidersFromEarlierFloorsAreNotPutOut(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	boardRider(e, 60, 9)
+	boardRider(e, 60, 9) // already over the limit when the car reaches 4
+	p := NewPassenger(1, 4, 9, 0)
+	e.AddPassenger(p)
+
+	runUntilIdle(e, 100)
+	if e.CurrentFloor != 9 || len(e.Occupants()) != 0 || p.State != PassengerArrived {
+		t.Errorf("expected everyone delivered to 9, got floor %d with %d riders (p %s)",
+			e.CurrentFloor, len(e.Occupants()), p.State)
+	}
+}
+
+func TestCapacity_NearFull(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	for range 7 {
+		boardRider(e, passengerWeight, 9)
+	}
+	if e.NearFull() {
+		t.Error("7 of 10 should not be near full")
+	}
+	boardRider(e, passengerWeight, 9)
+	if !e.NearFull() || e.WeightSensor() {
+		t.Errorf("8 of 10: expected near full but not full, got near=%v full=%v", e.NearFull(), e.WeightSensor())
+	}
+
+	e.SetCapacity(Capacity{MaxWeight: 1000, MaxPassengers: 8})
+	if !e.WeightSensor() {
+		t.Error("expected full by headcount")
+	}
+	e.SetCapacity(Capacity{MaxWeight: 1000})
+	if e.NearFull() || e.WeightSensor() {
+		t.Error("expected no headcount limit and no near-full threshold")
+	}
+}
+
+func TestDispatcher_SkipsNearFullCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 20)
+	near := elevatorAt(d, 0)
+	elevatorAt(d, 1).CurrentFloor = 15
+	for range 8 {
+		boardRider(near, passengerWeight, 20)
+	}
+
+	if chosen := d.Dispatch(Request{Floor: 3, Direction: DirUp, Type: HallCall}); chosen.CarID() != 2 {
+		t.Errorf("expected car 2 while car 1 is near full, got %d", chosen.CarID())
+	}
+
+	// With every car near full the call still goes somewhere.
+	for range 8 {
+		boardRider(elevatorAt(d, 1), passengerWeight, 1)
+	}
+	if chosen := d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}); chosen == nil {
+		t.Error("expected a car even when all are near full")
+	}
+}
+
+func TestDispatcher_SteppedOffPassengerTakesAnotherCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	full := elevatorAt(d, 0)
+	full.SetCapacity(Capacity{MaxWeight: 1000, MaxPassengers: 2})
+	elevatorAt(d, 1).CurrentFloor = 10
+	ps := []*Passenger{NewPassenger(1, 3, 8, 0), NewPassenger(2, 3, 8, 0), NewPassenger(3, 3, 8, 0)}
+	for _, p := range ps {
+		if car := d.DispatchPassenger(p); car != full {
+			t.Fatalf("passenger %d: expected car 1 from below, got car %d", p.ID, car.CarID())
+		}
+	}
+
+	for full.State != StateOverloaded {
+		d.StepAll()
+	}
+	d.StepAll() // passenger 3 steps off
+	if w := elevatorAt(d, 1).Waiting(); len(w) != 1 || w[0] != ps[2] {
+		t.Fatalf("expected passenger 3 handed to car 2, car 2 waiting for %v", w)
+	}
+
+	for range 50 {
+		d.StepAll()
+	}
+	for _, p := range ps {
+		if p.State != PassengerArrived {
+			t.Errorf("passenger %d: expected arrived, got %s", p.ID, p.State)
+		}
+	}
+}
+
+func TestSimulator_OverloadedPassengersTakeNextTrip(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	d.Elevators[0].SetCapacity(Capacity{MaxWeight: 100, MaxPassengers: 2})
+	s := NewSimulator(d, time.Second)
+	s.Timed = true
+	var ps []*Passenger
+	for i := range 5 {
+		ps = append(ps, s.AddPassenger(0, 1, 6+i))
+	}
+
+	r := s.Run()
+	if len(r.Passengers) != 5 {
+		t.Fatalf("expected all 5 delivered, got %d", len(r.Passengers))
+	}
+	// Two at a time: the fifth boards on the third trip.
+	if ps[4].BoardTime <= ps[1].AlightTime {
+		t.Errorf("expected passenger 5 to board after the first trip, boarded %v, first trip ended %v",
+			ps[4].BoardTime, ps[1].AlightTime)
+	}
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+var (
+	ErrControllerRunning = errors.New("controller already running")
+	ErrControllerStopped = errors.New("controller stopped")
+)
+
+// CarStatus is a point-in-time copy of one car's state, safe to use after
+// the controller's lock is released.
+type CarStatus struct {
+	ID        int
+	Floor     int
+	State     ElevatorState
+	Direction Direction
+	Pending   int
+}
+
+// callRequest is a button press travelling to the dispatch loop.
+type callRequest struct {
+	req   Request
+	reply chan int // assigned car ID, 0 if none; nil for fire-and-forget
+}
+
+// Controller runs a Dispatcher in real time.
+//
+// Run starts one goroutine per car, each stepping its car on its own
+// time.Ticker, and one dispatch goroutine that assigns button presses
+// arriving over a channel. Any number of goroutines may call Press, Submit
+// and Snapshot concurrently. The cars and the Dispatcher are not safe for
+// concurrent use themselves, so every access goes through one mutex; the
+// caller must not touch them directly while Run is active.
+type Controller struct {
+	Dispatcher *Dispatcher
+	Tick       time.Duration // time per car Step
+
+	// OnStep, if set, is called after every car step with the step
+	// description. It runs on the car's goroutine, outside the lock, as do
+	// the Dispatcher.Events subscribers.
+	OnStep func(carID int, msg string)
+
+	mu      sync.Mutex // guards Dispatcher and its cars
+	calls   chan callRequest
+	done    chan struct{} // closed when Run returns
+	running bool
+}
+
+// NewController creates a controller stepping every car once per tick.
+func NewController(d *Dispatcher, tick time.Duration) *Controller {
+	return &Controller{
+		Dispatcher: d,
+		Tick:       tick,
+		calls:      make(chan callRequest),
+		done:       make(chan struct{}),
+	}
+}
+
+// Run drives the fleet until ctx is cancelled, then stops every ticker and
+// waits for all goroutines to exit. It returns ctx.Err(), or
+// ErrControllerRunning if Run was already called.
+func (c *Controller) Run(ctx context.Context) error {
+	c.mu.Lock()
+	if c.running {
+		c.mu.Unlock()
+		return ErrControllerRunning
+	}
+	c.running = true
+	c.mu.Unlock()
+	defer close(c.done)
+
+	var wg sync.WaitGroup
+	for _, car := range c.Dispatcher.Elevators {
+		wg.Go(func() { c.runCar(ctx, car) })
+	}
+	wg.Go(func() { c.runDispatch(ctx) })
+	wg.Wait()
+	return ctx.Err()
+}
+
+// runCar steps one car on every tick.
+func (c *Controller) runCar(ctx context.Context, car CarModel) {
+	ticker := time.NewTicker(c.Tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			c.mu.Lock()
+			evs := c.Dispatcher.stepCar(car)
+			c.mu.Unlock()
+			c.Dispatcher.Events.Publish(evs)
+			if c.OnStep != nil {
+				c.OnStep(car.CarID(), FormatStep(evs))
+			}
+		}
+	}
+}
+
+// runDispatch assigns incoming calls and, once per tick, does the fleet
+// bookkeeping of StepAll (see Dispatcher.tick): faults, emergency power, the
+// watchdog, hall-call reassignment and sky-lobby transfers.
+func (c *Controller) runDispatch(ctx context.Context) {
+	ticker := time.NewTicker(c.Tick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case call := <-c.calls:
+			c.mu.Lock()
+			id := 0
+			if car := c.Dispatcher.Dispatch(call.req); car != nil {
+				id = car.CarID()
+			}
+			c.mu.Unlock()
+			if call.reply != nil {
+				call.reply <- id
+			}
+		case <-ticker.C:
+			c.mu.Lock()
+			c.Dispatcher.tick(func() {})
+			c.mu.Unlock()
+		}
+	}
+}
+
+// Submit hands r to the dispatch loop and waits for the assignment. It blocks
+// until Run is accepting calls, ctx is done, or the controller has stopped.
+// Returns the chosen car's ID, or 0 if no car could take the call.
+func (c *Controller) Submit(ctx context.Context, r Request) (int, error) {
+	reply := make(chan int, 1)
+	if err := c.send(ctx, callRequest{req: r, reply: reply}); err != nil {
+		return 0, err
+	}
+	select {
+	case id := <-reply:
+		return id, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// Press is a button press: it hands r to the dispatch loop without waiting
+// for the assignment.
+func (c *Controller) Press(ctx context.Context, r Request) error {
+	return c.send(ctx, callRequest{req: r})
+}
+
+func (c *Controller) send(ctx context.Context, call callRequest) error {
+	select {
+	case c.calls <- call:
+		return nil
+	case <-c.done:
+		return ErrControllerStopped
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Snapshot returns the state of every car.
+func (c *Controller) Snapshot() []CarStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cars := make([]CarStatus, len(c.Dispatcher.Elevators))
+	for i, e := range c.Dispatcher.Elevators {
+		cars[i] = CarStatus{
+			ID:        e.CarID(),
+			Floor:     e.Floor(),
+			State:     e.CurrentState(),
+			Direction: e.CurrentDirection(),
+			Pending:   e.PendingCount(),
+		}
+	}
+	return cars
+}
+
+// AllIdle reports whether every car is idle with no pending requests.
+func (c *Controller) AllIdle() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Dispatcher.AllIdle()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// startController runs c in the background; the returned func cancels it
+// and returns Run's error.
+func startController(t *testing.T, c *Controller) (context.Context, func() error) {
+	ctx, cancel := context.WithCancel(t.Context())
+	errc := make(chan error, 1)
+	go func() { errc <- c.Run(ctx) }()
+	return ctx, func() error {
+		cancel()
+		return <-errc
+	}
+}
+
+func TestController_ConcurrentPresses(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := NewController(NewDispatcher(3, 1, 20), 100*time.Millisecond)
+		ctx, stop := startController(t, c)
+
+		var wg sync.WaitGroup
+		for i := range 40 {
+			wg.Go(func() {
+				dir := DirUp
+				if i%2 == 1 {
+					dir = DirDown
+				}
+				if err := c.Press(ctx, Request{Floor: 1 + i%20, Direction: dir, Type: HallCall}); err != nil {
+					t.Errorf("press %d: %v", i, err)
+				}
+			})
+		}
+		// Readers race with the presses and the car goroutines.
+		for range 4 {
+			wg.Go(func() {
+				for range 10 {
+					c.Snapshot()
+					time.Sleep(50 * time.Millisecond)
+				}
+			})
+		}
+		wg.Wait()
+
+		time.Sleep(time.Minute)
+		if !c.AllIdle() {
+			t.Errorf("expected every call served, got %+v", c.Snapshot())
+		}
+		if err := stop(); !errors.Is(err, context.Canceled) {
+			t.Errorf("expected context.Canceled from Run, got %v", err)
+		}
+	})
+}
+
+func TestController_SubmitAndStep(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		d := NewDispatcher(2, 1, 10)
+		c := NewController(d, time.Second)
+		var steps atomic.Int64
+		c.OnStep = func(int, string) { steps.Add(1) }
+		ctx, stop := startController(t, c)
+
+		id, err := c.Submit(ctx, Request{Floor: 6, Direction: DirDown, Type: HallCall})
+		if err != nil || id != 1 {
+			t.Fatalf("expected car 1, got %d (%v)", id, err)
+		}
+
+		// Five floors up at one floor per tick.
+		time.Sleep(5*time.Second + time.Millisecond)
+		if s := c.Snapshot()[0]; s.Floor != 6 || s.State != StateDoorOpen {
+			t.Errorf("expected car 1 door open at 6, got %+v", s)
+		}
+		if steps.Load() == 0 {
+			t.Error("expected OnStep to be called")
+		}
+		stop()
+	})
+}
+
+func TestController_TransfersAtSkyLobby(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		d := newBankedDispatcher(WithSkyLobbies(11))
+		p := NewPassenger(1, 3, 18, 0)
+		d.DispatchPassenger(p)
+		c := NewController(d, time.Second)
+		_, stop := startController(t, c)
+
+		time.Sleep(time.Minute)
+		stop()
+		if p.State != PassengerArrived || p.Origin != 11 || p.Destination != 18 {
+			t.Errorf("expected arrival at 18 via 11, got %s %d→%d", p.State, p.Origin, p.Destination)
+		}
+	})
+}
+
+func TestController_NoCarAvailable(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		d := NewDispatcher(1, 1, 10)
+		d.SetMaintenance(1, true)
+		c := NewController(d, time.Second)
+		ctx, stop := startController(t, c)
+		defer stop()
+
+		if id, err := c.Submit(ctx, Request{Floor: 3, Direction: DirUp, Type: HallCall}); err != nil || id != 0 {
+			t.Errorf("expected no car (0, nil), got %d (%v)", id, err)
+		}
+	})
+}
+
+func TestController_Shutdown(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := NewController(NewDispatcher(2, 1, 10), time.Second)
+		_, stop := startController(t, c)
+		synctest.Wait()
+
+		if err := c.Run(t.Context()); !errors.Is(err, ErrControllerRunning) {
+			t.Errorf("expected ErrControllerRunning, got %v", err)
+		}
+		stop()
+
+		// Every goroutine has exited; presses are refused, not blocked.
+		if err := c.Press(t.Context(), Request{Floor: 3, Type: CabCall}); !errors.Is(err, ErrControllerStopped) {
+			t.Errorf("expected ErrControllerStopped, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"errors"
+	"slices"
+)
+
+var (
+	ErrInvalidTrip    = errors.New("invalid trip: origin and destination must be distinct floors in range")
+	ErrNoCarAvailable = errors.New("no car available")
+)
+
+// destinationKey identifies a group of passengers that can share a car:
+// same lobby keypad, same destination.
+type destinationKey struct {
+	origin, destination int
+}
+
+// DispatchDestination is the hall-keypad entry point of destination dispatch:
+// the passenger enters the target floor at the origin and is told which car
+// to board. Returns the assigned car's ID.
+func (d *Dispatcher) DispatchDestination(origin, destination int) (int, error) {
+	car, err := d.DispatchDestinationPassenger(NewPassenger(0, origin, destination, 0))
+	if err != nil {
+		return 0, err
+	}
+	return car.CarID(), nil
+}
+
+// DispatchDestinationPassenger assigns a passenger whose destination is known
+// up front.
+//
+// Passengers with the same origin and destination are grouped into the car
+// already assigned to that trip, as long as that car has not yet picked up at
+// the origin and is still one Dispatch would use: in service, not near full
+// and not reserved for a VIP. Otherwise the dispatch policy chooses among the
+// cars serving both floors; trips that need a sky-lobby transfer get
+// ErrNoCarAvailable, as does a policy pick that would refuse the cab stop.
+// Both the hall stop at the origin and the cab stop at the destination are
+// registered on the car immediately; the cab stop carries the trip direction
+// so it is kept for the leg after pickup even if the car passes the
+// destination first.
+func (d *Dispatcher) DispatchDestinationPassenger(p *Passenger) (CarModel, error) {
+	if p.Origin == p.Destination ||
+		p.Origin < d.MinFloor || p.Origin > d.MaxFloor ||
+		p.Destination < d.MinFloor || p.Destination > d.MaxFloor {
+		return nil, ErrInvalidTrip
+	}
+
+	key := destinationKey{p.Origin, p.Destination}
+	car, ok := d.destGroups[key]
+	if !ok || !awaitingPickup(car, p.Origin, p.Direction()) || !slices.Contains(d.available(), car) {
+		car = d.selectCar(p.HallRequest(), p.Destination)
+		if car == nil {
+			return nil, ErrNoCarAvailable
+		}
+	}
+	// Check the car takes the cab stop before queueing p, so an error leaves
+	// nothing behind.
+	if !car.Serves(p.Destination) || !slices.Contains(d.inService(), car) {
+		return nil, ErrNoCarAvailable
+	}
+
+	car.AddPassenger(p)
+	d.register(car, p.HallRequest())
+	if err := car.AddRequest(Request{Floor: p.Destination, Direction: p.Direction(), Type: CabCall}); err != nil {
+		return nil, err
+	}
+
+	if d.destGroups == nil {
+		d.destGroups = make(map[destinationKey]CarModel)
+	}
+	d.destGroups[key] = car
+	return car, nil
+}
+
+// awaitingPickup reports whether car still holds the hall stop at floor in dir.
+func awaitingPickup(car CarModel, floor int, dir Direction) bool {
+	up, down := car.StopsHallSnapshot()
+	if dir == DirUp {
+		return slices.Contains(up, floor)
+	}
+	return slices.Contains(down, floor)
+}
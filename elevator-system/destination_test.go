@@ -0,0 +1,141 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestDestinationDispatch_RegistersHallAndCabStop(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 1).CurrentFloor = 9
+
+	id, err := d.DispatchDestination(3, 7)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if id != 1 {
+		t.Fatalf("expected car 1 (nearest), got %d", id)
+	}
+
+	car := d.Elevators[0]
+	hallUp, _ := car.StopsHallSnapshot()
+	cabUp, _ := car.StopsCabSnapshot()
+	if !slices.Contains(hallUp, 3) {
+		t.Errorf("expected hall stop at 3 going up, got %v", hallUp)
+	}
+	if !slices.Contains(cabUp, 7) {
+		t.Errorf("expected cab stop at 7 going up, got %v", cabUp)
+	}
+}
+
+func TestDestinationDispatch_GroupsSharedDestination(t *testing.T) {
+	// Round robin would spread the requests; grouping keeps the same trip together.
+	d := NewDispatcher(3, 1, 10, WithPolicy(&RoundRobinPolicy{}))
+	elevatorAt(d, 0).CurrentFloor = 5
+	elevatorAt(d, 1).CurrentFloor = 5
+	elevatorAt(d, 2).CurrentFloor = 5
+
+	first, _ := d.DispatchDestination(1, 8)
+	second, _ := d.DispatchDestination(1, 8)
+	other, _ := d.DispatchDestination(1, 6)
+
+	if first != second {
+		t.Errorf("expected shared destination grouped into car %d, got %d", first, second)
+	}
+	if other == first {
+		t.Errorf("expected different destination to use the next car, got %d again", other)
+	}
+	if n := len(d.Elevators[first-1].Waiting()); n != 2 {
+		t.Errorf("expected 2 passengers waiting for car %d, got %d", first, n)
+	}
+}
+
+func TestDestinationDispatch_GroupClosesAfterPickup(t *testing.T) {
+	d := NewDispatcher(2, 1, 10, WithPolicy(&RoundRobinPolicy{}))
+	elevatorAt(d, 0).CurrentFloor = 4
+	elevatorAt(d, 1).CurrentFloor = 4
+
+	first, _ := d.DispatchDestination(2, 9)
+	car := d.Elevators[first-1]
+	for range 10 {
+		car.Step()
+		if !awaitingPickup(car, 2, DirUp) {
+			break
+		}
+	}
+
+	// The first car already left floor 2; a new passenger gets a fresh assignment.
+	second, _ := d.DispatchDestination(2, 9)
+	if second == first {
+		t.Errorf("expected a new car after pickup, got car %d again", second)
+	}
+}
+
+func TestDestinationDispatch_GroupSkipsNearFullCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 10, WithPolicy(&RoundRobinPolicy{}))
+	first, _ := d.DispatchDestination(3, 9)
+	for range 8 {
+		boardRider(elevatorAt(d, first-1), passengerWeight, 10)
+	}
+
+	if second, _ := d.DispatchDestination(3, 9); second == first {
+		t.Errorf("expected the near-full car %d left out of the group, got it again", first)
+	}
+}
+
+// firstCarPolicy ignores the candidates and always picks the first car.
+type firstCarPolicy struct{}
+
+func (firstCarPolicy) Select(d *Dispatcher, _ []CarModel, _ Request) CarModel { return d.Elevators[0] }
+
+func TestDestinationDispatch_RefusedCabStopLeavesNothingBehind(t *testing.T) {
+	d := NewDispatcher(2, 1, 10, WithPolicy(firstCarPolicy{}))
+	low := elevatorAt(d, 0)
+	low.SetServedFloors(1, 2, 3, 4, 5)
+
+	if _, err := d.DispatchDestination(3, 9); !errors.Is(err, ErrNoCarAvailable) {
+		t.Fatalf("expected ErrNoCarAvailable, got %v", err)
+	}
+	if len(low.Waiting()) != 0 || low.HasPendingRequests() || len(d.OutstandingCalls()) != 0 {
+		t.Errorf("expected nothing queued, got %d waiting, %d stops, calls %v",
+			len(low.Waiting()), low.PendingCount(), d.OutstandingCalls())
+	}
+}
+
+func TestDestinationDispatch_CabStopKeepsTripDirection(t *testing.T) {
+	// Car at 8 heading down; trip 2 → 5 goes up. The cab stop at 5 must wait
+	// for the up leg after pickup at 2.
+	d := NewDispatcher(1, 1, 10)
+	elevatorAt(d, 0).CurrentFloor = 8
+
+	p := NewPassenger(1, 2, 5, 0)
+	if _, err := d.DispatchDestinationPassenger(p); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stops := runUntilIdle(elevatorAt(d, 0), 100)
+
+	expected := []int{2, 5}
+	if !intSliceEqual(stops, expected) {
+		t.Errorf("expected stops %v, got %v", expected, stops)
+	}
+	if p.State != PassengerArrived {
+		t.Errorf("expected passenger delivered, got %s", p.State)
+	}
+}
+
+func TestDestinationDispatch_InvalidTrip(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+
+	for _, trip := range [][2]int{{3, 3}, {0, 5}, {5, 11}} {
+		if _, err := d.DispatchDestination(trip[0], trip[1]); !errors.Is(err, ErrInvalidTrip) {
+			t.Errorf("trip %v: expected ErrInvalidTrip, got %v", trip, err)
+		}
+	}
+
+	empty := NewDispatcherWithCars(1, 10, nil)
+	if _, err := empty.DispatchDestination(1, 5); !errors.Is(err, ErrNoCarAvailable) {
+		t.Errorf("expected ErrNoCarAvailable, got %v", err)
+	}
+}
@@ -1,115 +1,229 @@
 package main
 
 import (
+	"errors"
 	"fmt"
-	"math"
 )
 
+var ErrUnknownCar = errors.New("unknown car")
+
 // Dispatcher manages multiple elevators and assigns hall calls to the best one.
+// Cars are held as CarModel, so a fleet may mix stop-set implementations.
 type Dispatcher struct {
-	Elevators []*Elevator
+	Elevators []CarModel
 	MinFloor  int
 	MaxFloor  int
+	Policy    DispatchPolicy
+	Parking   ParkingPolicy // nil: idle cars stay where they stopped
+	Events    *EventBus     // step events of every car, published by StepAll
+
+	// CallTimeout is how many StepAll ticks a hall call may wait on one car
+	// before Reassign moves it (0: never for delay alone).
+	CallTimeout int
+
+	// SkyLobbies are the floors where a passenger changes cars when no car
+	// serves both ends of the trip (see WithSkyLobbies).
+	SkyLobbies []int
+
+	// EmergencyPowerCars is how many stranded cars StepAll lowers at once
+	// during a power failure (see WithEmergencyPower).
+	EmergencyPowerCars int
+
+	// Faults strikes cars with faults (nil: none). WatchdogTimeout is how
+	// many StepAll ticks a car with work may go without progress before it
+	// is taken out of service (0: never); see WithWatchdog.
+	Faults          FaultSource
+	WatchdogTimeout int
+
+	destGroups map[destinationKey]CarModel // destination dispatch: trip → assigned car
+	reserved   map[CarModel]bool           // cars held for a VIP call
+	calls      map[hallCallKey]*hallCall   // outstanding hall calls by holder
+	legs       map[*Passenger]int          // transfer trips on their first leg → final destination
+	ticks      int                         // StepAll ticks so far, for the fault source
+	watch      map[CarModel]*progress      // watchdog: last progress seen per car
+	failed     map[CarModel]bool           // cars the watchdog took out of service
+}
+
+// DispatcherOption configures a Dispatcher at construction time.
+type DispatcherOption func(*Dispatcher)
+
+// WithPolicy selects the dispatch strategy (default: NewCostPolicy()).
+func WithPolicy(p DispatchPolicy) DispatcherOption {
+	return func(d *Dispatcher) { d.Policy = p }
+}
+
+// WithParking sets where idle cars wait for calls; see ParkIdle. Only the
+// Simulator, which knows the time of day, parks cars on its own. StepAll
+// does not: its caller calls ParkIdle between steps. The Controller does not
+// park idle cars.
+func WithParking(p ParkingPolicy) DispatcherOption {
+	return func(d *Dispatcher) { d.Parking = p }
 }
 
-// NewDispatcher creates a dispatcher with n elevators.
-func NewDispatcher(n, minFloor, maxFloor int) *Dispatcher {
-	elevators := make([]*Elevator, n)
+// NewDispatcher creates a dispatcher with n []bool elevators.
+func NewDispatcher(n, minFloor, maxFloor int, opts ...DispatcherOption) *Dispatcher {
+	elevators := make([]CarModel, n)
 	for i := range n {
 		elevators[i] = NewElevator(i+1, minFloor, maxFloor)
 	}
-	return &Dispatcher{
-		Elevators: elevators,
+	return NewDispatcherWithCars(minFloor, maxFloor, elevators, opts...)
+}
+
+// NewDispatcherWithCars creates a dispatcher over an existing fleet,
+// e.g. bitmask cars in a low-rise bank and bitset cars in a tower.
+func NewDispatcherWithCars(minFloor, maxFloor int, cars []CarModel, opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		Elevators: cars,
 		MinFloor:  minFloor,
 		MaxFloor:  maxFloor,
-	}
-}
+		Policy:    NewCostPolicy(),
+		Events:    NewEventBus(),
 
-// Dispatch assigns a hall call to the best elevator using a cost function.
-// The cost considers:
-//   - Distance from the elevator to the request floor
-//   - Direction alignment bonus (same direction = lower cost)
-//   - Current load (number of pending requests)
-func (d *Dispatcher) Dispatch(r Request) *Elevator {
-	if len(d.Elevators) == 0 {
-		return nil
+		EmergencyPowerCars: defaultEmergencyPowerCars,
 	}
-
-	var best *Elevator
-	bestCost := math.MaxFloat64
-
-	for _, e := range d.Elevators {
-		cost := d.cost(e, r)
-		if cost < bestCost {
-			bestCost = cost
-			best = e
-		}
+	for _, opt := range opts {
+		opt(d)
 	}
+	return d
+}
 
+// Dispatch assigns a hall call to the elevator chosen by the dispatch policy.
+func (d *Dispatcher) Dispatch(r Request) CarModel {
+	best := d.selectCar(r)
 	if best != nil {
 		best.AddRequest(r)
+		d.register(best, r)
 	}
 	return best
 }
 
-// cost calculates the cost for an elevator to serve a request.
+// DispatchPassenger assigns a passenger to the best elevator for its hall call
+// among the cars that serve both its origin and destination. The car boards
+// the passenger when it serves that call.
 //
-// Cost formula:
-//
-//	base = |currentFloor - requestFloor|
-//	if elevator is idle: cost = base
-//	if elevator is moving toward the request and same direction: cost = base
-//	if elevator is moving toward but opposite direction: cost = base + N/2
-//	if elevator is moving away: cost = distance_to_end + end_to_request
-//
-// A small penalty is added for each pending request to prefer less-loaded elevators.
-func (d *Dispatcher) cost(e *Elevator, r Request) float64 {
-	distance := abs(e.CurrentFloor - r.Floor)
+// If no car serves both, the trip is split at a sky lobby: the returned car
+// takes the passenger there, and the second leg is dispatched once it steps
+// out (by StepAll, the Controller or the Simulator). StepAll and the
+// Controller keep trying each tick while no car can take the second leg.
+// Returns nil if the trip cannot be served.
+func (d *Dispatcher) DispatchPassenger(p *Passenger) CarModel {
+	if !d.planTransfer(p) {
+		return nil
+	}
+	best := d.selectCar(p.HallRequest(), p.Destination)
+	if best == nil && d.changesCars(p) {
+		p.Destination = d.legs[p]
+		delete(d.legs, p)
+	}
+	if best != nil {
+		best.AddPassenger(p)
+		d.register(best, p.HallRequest())
+	}
+	return best
+}
 
-	// Idle elevator: pure distance.
-	if e.State == StateIdle || e.Direction == DirIdle {
-		return float64(distance) + 0.5*float64(e.PendingCount())
+// selectCar returns the elevator the policy picks for r among the cars in
+// service that stop at r.Floor and every floor in also, or nil if there are
+// none.
+//
+// A VIP call gets an idle car of its own when one is free. Otherwise it goes
+// to the policy's choice like any call and preempts that car's LOOK order.
+// Reserved and near-full cars are skipped unless no other car is in service.
+func (d *Dispatcher) selectCar(r Request, also ...int) CarModel {
+	d.observe(r)
+	floors := append([]int{r.Floor}, also...)
+	if r.Priority > PriorityNormal {
+		if car := d.reserveVIPCar(r, floors); car != nil {
+			return car
+		}
+	}
+	cars := serving(d.available(), floors...)
+	if len(cars) == 0 {
+		cars = serving(d.inService(), floors...)
 	}
+	if len(cars) == 0 {
+		return nil
+	}
+	return d.Policy.Select(d, cars, r)
+}
 
-	movingToward := (e.Direction == DirUp && r.Floor >= e.CurrentFloor) ||
-		(e.Direction == DirDown && r.Floor <= e.CurrentFloor)
+// available returns the cars that accept new requests, are not near full
+// and are not reserved for a VIP call. A reservation ends once the car has
+// served its VIP stops.
+func (d *Dispatcher) available() []CarModel {
+	cars := make([]CarModel, 0, len(d.Elevators))
+	for _, e := range d.inService() {
+		if e.NearFull() {
+			continue
+		}
+		if d.reserved[e] {
+			if e.HasVIPRequests() {
+				continue
+			}
+			delete(d.reserved, e)
+		}
+		cars = append(cars, e)
+	}
+	return cars
+}
 
-	if movingToward {
-		sameDir := r.Type == CabCall || r.Direction == e.Direction
-		if sameDir {
-			// Best case: on the way and same direction.
-			return float64(distance) + 0.5*float64(e.PendingCount())
+// inService returns the cars not in maintenance, fire service or an
+// emergency stop, and not failed.
+func (d *Dispatcher) inService() []CarModel {
+	cars := make([]CarModel, 0, len(d.Elevators))
+	for _, e := range d.Elevators {
+		if !e.InMaintenance() && e.FirePhase() == FireOff && e.Emergency() == EmergencyNone && !d.failed[e] {
+			cars = append(cars, e)
 		}
-		// On the way but opposite direction — will pass through but won't pick up.
-		// Needs to go to end first, then come back.
-		span := float64(d.MaxFloor - d.MinFloor)
-		return float64(distance) + span/2 + 0.5*float64(e.PendingCount())
 	}
+	return cars
+}
 
-	// Moving away: must go to end, reverse, then reach the floor.
-	var detour int
-	if e.Direction == DirUp {
-		detour = (d.MaxFloor - e.CurrentFloor) + (d.MaxFloor - r.Floor)
-	} else {
-		detour = (e.CurrentFloor - d.MinFloor) + (r.Floor - d.MinFloor)
+// car returns the elevator with the given ID.
+func (d *Dispatcher) car(id int) (CarModel, error) {
+	for _, e := range d.Elevators {
+		if e.CarID() == id {
+			return e, nil
+		}
 	}
-	return float64(detour) + 0.5*float64(e.PendingCount())
+	return nil, ErrUnknownCar
 }
 
-// StepAll advances all elevators by one time unit.
+// StepAll advances all elevators by one time unit, publishes their step
+// events, then reassigns hall calls stranded on overloaded, delayed, failed
+// or removed cars. Before the cars step it injects the fault source's
+// faults and, during a power failure, hands emergency power to the next
+// stranded cars; after, the watchdog checks every car for progress.
 // Returns descriptions of each elevator's action.
 func (d *Dispatcher) StepAll() []string {
 	msgs := make([]string, len(d.Elevators))
-	for i, e := range d.Elevators {
-		msgs[i] = e.Step()
-	}
+	d.tick(func() {
+		for i, e := range d.Elevators {
+			evs := d.stepCar(e)
+			d.Events.Publish(evs)
+			msgs[i] = FormatStep(evs)
+		}
+	})
 	return msgs
 }
 
+// tick does one tick of fleet bookkeeping around step, which advances the
+// cars: faults and emergency power before, the watchdog, hall-call
+// reassignment and sky-lobby transfers after. The Controller, whose cars step
+// on their own goroutines, passes a step that does nothing.
+func (d *Dispatcher) tick(step func()) {
+	d.injectFaults()
+	d.tickEmergencyPower()
+	step()
+	d.tickWatchdog()
+	d.tickCalls()
+	d.tickTransfers()
+}
+
 // AllIdle returns true if every elevator is idle with no pending requests.
 func (d *Dispatcher) AllIdle() bool {
 	for _, e := range d.Elevators {
-		if e.State != StateIdle || e.HasPendingRequests() {
+		if e.CurrentState() != StateIdle || e.HasPendingRequests() {
 			return false
 		}
 	}
@@ -120,8 +234,24 @@ func (d *Dispatcher) AllIdle() bool {
 func (d *Dispatcher) Status() string {
 	s := ""
 	for _, e := range d.Elevators {
-		s += fmt.Sprintf("  [E%d] floor=%d state=%s dir=%s pending=%d\n",
-			e.ID, e.CurrentFloor, e.State, e.Direction, e.PendingCount())
+		s += fmt.Sprintf("  [E%d] floor=%d state=%s dir=%s pending=%d",
+			e.CarID(), e.Floor(), e.CurrentState(), e.CurrentDirection(), e.PendingCount())
+		if e.InMaintenance() {
+			s += " (maintenance)"
+		}
+		if ph := e.FirePhase(); ph != FireOff {
+			s += fmt.Sprintf(" (fire %s)", ph)
+		}
+		if m := e.Emergency(); m != EmergencyNone {
+			s += fmt.Sprintf(" (emergency: %s)", m)
+		}
+		if f := e.Fault(); f != FaultNone {
+			s += fmt.Sprintf(" (fault: %s)", f)
+		}
+		if d.failed[e] {
+			s += " (failed)"
+		}
+		s += "\n"
 	}
 	return s
 }
@@ -4,18 +4,23 @@ import (
 	"testing"
 )
 
+// elevatorAt returns the i-th car of a dispatcher built by NewDispatcher.
+func elevatorAt(d *Dispatcher, i int) *Elevator {
+	return d.Elevators[i].(*Elevator)
+}
+
 func TestDispatcher_ClosestElevatorSelected(t *testing.T) {
 	d := NewDispatcher(3, 1, 10)
 	// Place elevators at different floors.
-	d.Elevators[0].CurrentFloor = 1
-	d.Elevators[1].CurrentFloor = 5
-	d.Elevators[2].CurrentFloor = 9
+	elevatorAt(d, 0).CurrentFloor = 1
+	elevatorAt(d, 1).CurrentFloor = 5
+	elevatorAt(d, 2).CurrentFloor = 9
 
 	// Hall call at floor 6 going up — elevator 2 (floor 5) should be selected.
 	chosen := d.Dispatch(Request{Floor: 6, Direction: DirUp, Type: HallCall})
 
-	if chosen.ID != 2 {
-		t.Errorf("expected elevator 2, got elevator %d", chosen.ID)
+	if chosen.CarID() != 2 {
+		t.Errorf("expected elevator 2, got elevator %d", chosen.CarID())
 	}
 }
 
@@ -23,21 +28,21 @@ func TestDispatcher_SameDirectionPreferred(t *testing.T) {
 	d := NewDispatcher(2, 1, 10)
 
 	// Elevator 1 at floor 3 moving up.
-	d.Elevators[0].CurrentFloor = 3
-	d.Elevators[0].State = StateMovingUp
-	d.Elevators[0].Direction = DirUp
+	elevatorAt(d, 0).CurrentFloor = 3
+	elevatorAt(d, 0).State = StateMovingUp
+	elevatorAt(d, 0).Direction = DirUp
 
 	// Elevator 2 at floor 4 moving down.
-	d.Elevators[1].CurrentFloor = 4
-	d.Elevators[1].State = StateMovingDown
-	d.Elevators[1].Direction = DirDown
+	elevatorAt(d, 1).CurrentFloor = 4
+	elevatorAt(d, 1).State = StateMovingDown
+	elevatorAt(d, 1).Direction = DirDown
 
 	// Hall call at floor 6 going up — elevator 1 (moving up) should be preferred
 	// even though elevator 2 is slightly closer.
 	chosen := d.Dispatch(Request{Floor: 6, Direction: DirUp, Type: HallCall})
 
-	if chosen.ID != 1 {
-		t.Errorf("expected elevator 1 (same direction), got elevator %d", chosen.ID)
+	if chosen.CarID() != 1 {
+		t.Errorf("expected elevator 1 (same direction), got elevator %d", chosen.CarID())
 	}
 }
 
@@ -45,20 +50,20 @@ func TestDispatcher_IdleElevatorPreferred(t *testing.T) {
 	d := NewDispatcher(2, 1, 10)
 
 	// Elevator 1 at floor 5, idle.
-	d.Elevators[0].CurrentFloor = 5
+	elevatorAt(d, 0).CurrentFloor = 5
 
 	// Elevator 2 at floor 5, moving up with pending requests.
-	d.Elevators[1].CurrentFloor = 5
-	d.Elevators[1].State = StateMovingUp
-	d.Elevators[1].Direction = DirUp
-	d.Elevators[1].AddRequest(Request{Floor: 8, Type: CabCall})
-	d.Elevators[1].AddRequest(Request{Floor: 9, Type: CabCall})
+	elevatorAt(d, 1).CurrentFloor = 5
+	elevatorAt(d, 1).State = StateMovingUp
+	elevatorAt(d, 1).Direction = DirUp
+	elevatorAt(d, 1).AddRequest(Request{Floor: 8, Type: CabCall})
+	elevatorAt(d, 1).AddRequest(Request{Floor: 9, Type: CabCall})
 
 	// Hall call at floor 3 going down.
 	chosen := d.Dispatch(Request{Floor: 3, Direction: DirDown, Type: HallCall})
 
-	if chosen.ID != 1 {
-		t.Errorf("expected idle elevator 1, got elevator %d", chosen.ID)
+	if chosen.CarID() != 1 {
+		t.Errorf("expected idle elevator 1, got elevator %d", chosen.CarID())
 	}
 }
 
@@ -87,8 +92,8 @@ func TestDispatcher_MultipleRequests_Distribution(t *testing.T) {
 	e1 := d.Dispatch(Request{Floor: 2, Direction: DirUp, Type: HallCall})
 	e2 := d.Dispatch(Request{Floor: 9, Direction: DirDown, Type: HallCall})
 
-	if e1.ID == e2.ID {
-		t.Errorf("expected different elevators for opposite requests, both got %d", e1.ID)
+	if e1.CarID() == e2.CarID() {
+		t.Errorf("expected different elevators for opposite requests, both got %d", e1.CarID())
 	}
 }
 
@@ -104,3 +109,53 @@ func TestDispatcher_AllIdle(t *testing.T) {
 		t.Error("expected not all idle after dispatching a request")
 	}
 }
+
+func TestDispatcher_MixedFleet(t *testing.T) {
+	low := NewBitmaskElevator(1, 1, 10)
+	tower := NewBitsetElevator(2, 1, 10)
+	plain := NewElevator(3, 1, 10)
+	low.CurrentFloor = 2
+	tower.CurrentFloor = 9
+	plain.CurrentFloor = 5
+
+	d := NewDispatcherWithCars(1, 10, []CarModel{low, tower, plain})
+
+	if chosen := d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall}); chosen.CarID() != 1 {
+		t.Errorf("expected bitmask car 1, got %d", chosen.CarID())
+	}
+	if chosen := d.Dispatch(Request{Floor: 10, Direction: DirDown, Type: HallCall}); chosen.CarID() != 2 {
+		t.Errorf("expected bitset car 2, got %d", chosen.CarID())
+	}
+	if chosen := d.Dispatch(Request{Floor: 6, Direction: DirUp, Type: HallCall}); chosen.CarID() != 3 {
+		t.Errorf("expected []bool car 3, got %d", chosen.CarID())
+	}
+
+	for range 50 {
+		d.StepAll()
+		if d.AllIdle() {
+			break
+		}
+	}
+	if !d.AllIdle() {
+		t.Fatalf("mixed fleet did not drain:\n%s", d.Status())
+	}
+	if low.CurrentFloor != 1 || tower.CurrentFloor != 10 || plain.CurrentFloor != 6 {
+		t.Errorf("unexpected final floors: low=%d tower=%d plain=%d",
+			low.CurrentFloor, tower.CurrentFloor, plain.CurrentFloor)
+	}
+}
+
+func TestDispatcher_DispatchPassenger(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 1).CurrentFloor = 9
+
+	p := NewPassenger(1, 8, 2, 0)
+	chosen := d.DispatchPassenger(p)
+
+	if chosen.CarID() != 2 {
+		t.Fatalf("expected elevator 2, got elevator %d", chosen.CarID())
+	}
+	if w := chosen.Waiting(); len(w) != 1 || w[0] != p {
+		t.Errorf("expected passenger waiting for elevator 2, got %v", w)
+	}
+}
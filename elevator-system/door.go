@@ -0,0 +1,52 @@
+package main
+
+// DoorConfig is how a car's door answers its hold button and obstruction
+// sensor.
+type DoorConfig struct {
+	MaxHoldSteps int // longest the door-open-hold button keeps the door open (0: button disabled)
+	NudgeAfter   int // obstruction reopens in a row before the door nudges closed (0: never)
+}
+
+// DefaultDoorConfig holds the door up to 10 steps and nudges after 3 reopens.
+func DefaultDoorConfig() DoorConfig {
+	return DoorConfig{
+		MaxHoldSteps: 10,
+		NudgeAfter:   3,
+	}
+}
+
+// nudgeSteps is how many steps a nudging door takes to close.
+const nudgeSteps = 2
+
+// door holds a car's door configuration and obstruction sensor. The hold and
+// close buttons act on the car's state (StateDoorHold) and door timer.
+type door struct {
+	doorConfig DoorConfig
+	obstructed bool // the light curtain across the doorway is blocked
+	reopens    int  // obstruction reopens since the door last closed
+	opening    bool // the door opened between steps; the next step reports it
+}
+
+func newDoor() door {
+	return door{doorConfig: DefaultDoorConfig()}
+}
+
+// DoorConfig returns the car's door configuration.
+func (d *door) DoorConfig() DoorConfig { return d.doorConfig }
+
+// SetDoorConfig replaces the car's door configuration.
+func (d *door) SetDoorConfig(c DoorConfig) { d.doorConfig = c }
+
+// SetObstructed reports the obstruction sensor: while it is blocked, a door
+// about to close reopens instead.
+func (d *door) SetObstructed(on bool) { d.obstructed = on }
+
+// Obstructed reports whether the obstruction sensor is blocked.
+func (d *door) Obstructed() bool { return d.obstructed }
+
+// reopen counts an obstruction reopen and reports whether the door has now
+// reopened often enough to nudge.
+func (d *door) reopen() bool {
+	d.reopens++
+	return d.doorConfig.NudgeAfter > 0 && d.reopens >= d.doorConfig.NudgeAfter
+}
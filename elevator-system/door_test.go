@@ -0,0 +1,146 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// openDoorAt1 returns a car standing at floor 1 with its door open and a cab
+// call to 5 waiting.
+func openDoorAt1() *Elevator {
+	e := NewElevator(1, 1, 10)
+	e.AddRequest(Request{Floor: 1, Type: CabCall})
+	e.AddRequest(Request{Floor: 5, Type: CabCall})
+	return e
+}
+
+// stepKinds steps car n times and returns what each step did: the kind of
+// its first event past the door opening reported for openDoorAt1.
+func stepKinds(car CarModel, n int) []StepKind {
+	kinds := make([]StepKind, n)
+	for i := range kinds {
+		body, _ := stepBody(car.StepEvents())
+		kinds[i] = body[0].Kind
+	}
+	return kinds
+}
+
+func TestDoor_ObstructionReopensThenNudges(t *testing.T) {
+	car := openDoorAt1()
+	car.SetObstructed(true)
+
+	want := []StepKind{
+		StepDoorHeld, StepDoorReopened,
+		StepDoorHeld, StepDoorReopened,
+		StepDoorHeld, StepNudging, // third reopen: nudge instead
+		StepNudging, StepDoorClosed,
+	}
+	if got := stepKinds(car, len(want)); !slices.Equal(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+	if car.CurrentState() != StateMovingUp || car.Floor() != 1 {
+		t.Errorf("expected the car on its way up from 1, got %s at %d", car.CurrentState(), car.Floor())
+	}
+}
+
+func TestDoor_ReopenCountResetsOnClose(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.SetDoorConfig(DoorConfig{MaxHoldSteps: 10, NudgeAfter: 2})
+	e.AddRequest(Request{Floor: 1, Type: CabCall})
+	e.AddRequest(Request{Floor: 3, Type: CabCall})
+	e.SetObstructed(true)
+	if got := stepKinds(e, 2); got[1] != StepDoorReopened {
+		t.Fatalf("expected a reopen, got %v", got)
+	}
+	e.SetObstructed(false)
+	runCarUntilIdle(e, 10)
+
+	// A fresh stop starts counting again: one reopen does not nudge.
+	e.AddRequest(Request{Floor: 3, Type: CabCall})
+	e.SetObstructed(true)
+	if got := stepKinds(e, 2); got[1] != StepDoorReopened {
+		t.Errorf("expected a reopen, not a nudge, got %v", got)
+	}
+}
+
+func TestDoor_NudgingDisabled(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.SetDoorConfig(DoorConfig{NudgeAfter: 0})
+	e.AddRequest(Request{Floor: 1, Type: CabCall})
+	e.SetObstructed(true)
+	for range 20 {
+		e.Step()
+	}
+	if e.State != StateDoorOpen {
+		t.Errorf("expected the door kept open while obstructed, got %s", e.State)
+	}
+}
+
+func TestDoor_HoldButton(t *testing.T) {
+	car := openDoorAt1()
+	car.SetDoorConfig(DoorConfig{MaxHoldSteps: 4, NudgeAfter: 3})
+	if !car.HoldDoor() || car.CurrentState() != StateDoorHold {
+		t.Fatalf("expected the door held, got %s", car.CurrentState())
+	}
+
+	// A call at the floor while held boards without ending the hold.
+	car.AddRequest(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+	if car.CurrentState() != StateDoorHold {
+		t.Errorf("expected the hold kept, got %s", car.CurrentState())
+	}
+
+	want := []StepKind{StepHoldOpen, StepHoldOpen, StepHoldOpen, StepDoorClosed}
+	if got := stepKinds(car, len(want)); !slices.Equal(got, want) {
+		t.Errorf("expected the hold to run out after 4 steps, got %v", got)
+	}
+	if car.HoldDoor() {
+		t.Error("expected the hold button ignored with the door closed")
+	}
+}
+
+func TestDoor_HoldReleasedByButtons(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.AddRequest(Request{Floor: 1, Type: CabCall})
+	e.HoldDoor()
+	e.HoldDoor() // pressed again: released
+	if e.State != StateDoorOpen || e.doorTimer != doorOpenSteps {
+		t.Errorf("expected a normal dwell after release, got %s (%d)", e.State, e.doorTimer)
+	}
+
+	e.HoldDoor()
+	if !e.CloseDoor() {
+		t.Fatal("expected the close button to end the hold")
+	}
+	if got := stepKinds(e, 1); got[0] != StepDoorClosed {
+		t.Errorf("expected the door closed at once, got %v", got[0])
+	}
+
+	e.SetDoorConfig(DoorConfig{MaxHoldSteps: 0, NudgeAfter: 3})
+	e.AddRequest(Request{Floor: 1, Type: CabCall})
+	if e.HoldDoor() {
+		t.Error("expected the hold button disabled")
+	}
+}
+
+func TestDoor_CloseButton(t *testing.T) {
+	car := openDoorAt1()
+	if !car.CloseDoor() {
+		t.Fatal("expected the close button to act on an open door")
+	}
+	if got := stepKinds(car, 1); got[0] != StepDoorClosed {
+		t.Errorf("expected the dwell cut short, got %v", got[0])
+	}
+	if car.CloseDoor() {
+		t.Error("expected the close button ignored while moving")
+	}
+
+	// At 5, an obstruction still wins over the close button.
+	for car.CurrentState() != StateDoorOpen {
+		car.Step()
+	}
+	car.SetObstructed(true)
+	car.CloseDoor()
+	if evs := car.StepEvents(); evs[0].Kind != StepDoorReopened {
+		t.Errorf("expected the door reopened, got %v", evs[0].Kind)
+	}
+}
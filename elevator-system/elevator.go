@@ -1,7 +1,5 @@
 package main
 
-import "fmt"
-
 // Elevator represents a single elevator car.
 // It implements a LOOK algorithm (variant of SCAN):
 //   - Serve all requests in the current direction first
@@ -31,12 +29,24 @@ type Elevator struct {
 	// doorTimer counts down steps while the door is open.
 	doorTimer int
 
-	currentWeight int
-	maxWeight     int
+	// maintenance: finish current stops, accept no new requests.
+	maintenance bool
+
+	// parking: moving to parkFloor with no request, dropped on the first real one.
+	parking   bool
+	parkFloor int
+
+	cabin
+	vipQueue
+	motion
+	zone
+	fireService
+	emergency
+	faultState
+	door
 }
 
-const doorOpenSteps = 2    // Number of steps the door stays open
-const passengerWeight = 10 // Simulated weight per passenger boarding/alighting
+const doorOpenSteps = 2 // Number of steps the door stays open
 
 // NewElevator creates an elevator starting at the given floor.
 func NewElevator(id, minFloor, maxFloor int) *Elevator {
@@ -54,25 +64,68 @@ func NewElevator(id, minFloor, maxFloor int) *Elevator {
 		cabDownStops:  make([]bool, n),
 		minRequest:    maxFloor + 1, // > maxRequest means empty
 		maxRequest:    minFloor - 1,
-		currentWeight: 0,
-		maxWeight:     100,
+		cabin:         newCabin(),
+		door:          newDoor(),
+		motion:        newMotion(),
+		zone:          newZone(minFloor, maxFloor),
 	}
 }
 
+// CarID returns the elevator's identifier.
+func (e *Elevator) CarID() int { return e.ID }
+
+// Floor returns the floor the elevator is currently at.
+func (e *Elevator) Floor() int { return e.CurrentFloor }
+
+// CurrentState returns the elevator's state.
+func (e *Elevator) CurrentState() ElevatorState { return e.State }
+
+// CurrentDirection returns the elevator's travel direction.
+func (e *Elevator) CurrentDirection() Direction { return e.Direction }
+
 // idx converts a floor number to the array index.
 func (e *Elevator) idx(floor int) int {
 	return floor - e.MinFloor
 }
 
 // AddRequest adds a request to the elevator's stop sets using the LOOK strategy.
-func (e *Elevator) AddRequest(r Request) {
-	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor {
-		return
+// It returns ErrOutOfService after an emergency stop (see SeismicStop and
+// PowerFailure), ErrFireService for a request fire service does not honour
+// (see FireRecall), ErrInMaintenance while the elevator is in maintenance and
+// ErrFloorNotServed for a floor outside its zone (see SetServedFloors).
+func (e *Elevator) AddRequest(r Request) error {
+	if e.mode != EmergencyNone {
+		return ErrOutOfService
+	}
+	if e.refuses(r) {
+		return ErrFireService
 	}
-	if r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State == StateDoorOpen) {
+	if e.maintenance {
+		return ErrInMaintenance
+	}
+	return e.addRequest(r)
+}
+
+// addRequest registers a stop; openDoor also uses it for boarding passengers'
+// cab calls, which must be honoured even in maintenance.
+func (e *Elevator) addRequest(r Request) error {
+	if !e.Serves(r.Floor) {
+		return ErrFloorNotServed
+	}
+	if e.parking {
+		e.stopParking()
+	}
+	atFloor := r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State.loading())
+	vip := r.Priority > PriorityNormal && (atFloor || r.Floor != e.CurrentFloor)
+	if vip {
+		e.pushVIP(r.Floor)
+	}
+	if atFloor {
 		// Already at this floor and idle/door-open — open door, serve both directions.
+		// A shut door opens between steps; the next step reports it.
+		e.opening = e.opening || e.State == StateIdle
 		e.openDoor(DirIdle)
-		return
+		return nil
 	}
 
 	i := e.idx(r.Floor)
@@ -85,11 +138,15 @@ func (e *Elevator) AddRequest(r Request) {
 			e.hallDownStops[i] = true
 		}
 	case CabCall:
-		// Cab call: place based on relative position and current direction.
-		if r.Floor > e.CurrentFloor {
+		// Cab call: place by trip direction if known, else by relative position.
+		switch r.cabDirection(e.CurrentFloor) {
+		case DirUp:
 			e.cabUpStops[i] = true
-		} else if r.Floor < e.CurrentFloor {
+		case DirDown:
 			e.cabDownStops[i] = true
+		default:
+			// The car is leaving this floor: a cab call for it is not latched.
+			return nil
 		}
 	}
 
@@ -111,37 +168,545 @@ func (e *Elevator) AddRequest(r Request) {
 			e.State = StateMovingDown
 		}
 	}
+	if vip {
+		// Preempt: a moving car may have to turn around for the VIP stop.
+		e.reconsiderDirection()
+	}
+	return nil
+}
+
+// AddPassenger assigns a waiting passenger to this car and places its hall call.
+// The passenger boards when the car serves that call.
+func (e *Elevator) AddPassenger(p *Passenger) {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(p.Origin) {
+		return
+	}
+	e.await(p)
+	e.AddRequest(p.HallRequest())
+}
+
+// SetMaintenance takes the elevator out of service or puts it back.
+// In maintenance it finishes the stops it already has but refuses new requests.
+func (e *Elevator) SetMaintenance(on bool) { e.maintenance = on }
+
+// InMaintenance reports whether the elevator is in maintenance.
+func (e *Elevator) InMaintenance() bool { return e.maintenance }
+
+// Park sends an idle car to floor to wait for calls there. The move does not
+// open the door and is abandoned as soon as the car gets a real request.
+// Returns false if the car is busy, in maintenance, fire service or an
+// emergency stop, or does not serve floor.
+func (e *Elevator) Park(floor int) bool {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(floor) ||
+		e.HasPendingRequests() || (e.State != StateIdle && !e.parking) {
+		return false
+	}
+	e.stopParking()
+	switch {
+	case floor > e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	case floor < e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+	return true
+}
+
+// Parking reports whether the car is on its way to a parking floor.
+func (e *Elevator) Parking() bool { return e.parking }
+
+// HoldDoor is the door-open-hold button in the cab. An open door stays open
+// until the button is pressed again, the door-close button is pressed or
+// DoorConfig.MaxHoldSteps pass, whichever comes first. Returns false if the
+// door is not open for passengers or the button is disabled.
+func (e *Elevator) HoldDoor() bool {
+	switch {
+	case e.State == StateDoorOpen && e.doorConfig.MaxHoldSteps > 0:
+		e.State, e.doorTimer = StateDoorHold, e.doorConfig.MaxHoldSteps
+	case e.State == StateDoorHold:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	default:
+		return false
+	}
+	return true
+}
+
+// CloseDoor is the door-close button in the cab: an open or held door closes
+// on the next step, unless the obstruction sensor reopens it. Returns false
+// if the door is not open for passengers.
+func (e *Elevator) CloseDoor() bool {
+	if e.State != StateDoorOpen && e.State != StateDoorHold {
+		return false
+	}
+	e.State, e.doorTimer = StateDoorOpen, 1
+	return true
+}
+
+// FireRecall puts the car under Phase I fire recall: every stop is cancelled,
+// waiting passengers are turned away and the car runs non-stop to floor,
+// where it lets its riders out and parks with the door held open. A car with
+// its door open elsewhere closes it first; one moving away turns around.
+// A car already under firefighter control (Phase II) stays with the
+// firefighter and only learns the recall floor.
+// Returns ErrFloorNotServed if the car does not serve floor.
+func (e *Elevator) FireRecall(floor int) error {
+	if !e.Serves(floor) {
+		return ErrFloorNotServed
+	}
+	e.recall, e.recallFloor = true, floor
+	if e.firefighter {
+		return nil
+	}
+	e.clearStops()
+	e.runToRecall()
+	return nil
+}
+
+// EndFireRecall resets Phase I. A recalled car goes back into service where
+// it stands; one under firefighter control stays in Phase II until its key
+// is switched off.
+func (e *Elevator) EndFireRecall() {
+	if !e.recall {
+		return
+	}
+	e.recall = false
+	if !e.firefighter {
+		e.resumeService()
+	}
+}
+
+// SetFirefighterService turns the car's Phase II key switch.
+//
+// On, the car answers only its own cab calls: it closes its door once one is
+// registered and holds the door open at each stop until the next. It can only
+// be switched on in a car parked at its recall floor under Phase I.
+// Off, its cab calls are cancelled and it runs non-stop back to the recall
+// floor, or, if Phase I has been reset, goes back into service.
+// Returns ErrNotRecalled if switched on in a car not parked by the recall.
+func (e *Elevator) SetFirefighterService(on bool) error {
+	if on == e.firefighter {
+		return nil
+	}
+	if on {
+		if !e.recalling() || e.State != StateFireService {
+			return ErrNotRecalled
+		}
+		e.firefighter = true
+		return nil
+	}
+	e.firefighter = false
+	e.clearStops()
+	if e.recall {
+		e.runToRecall()
+	} else {
+		e.resumeService()
+	}
+	return nil
+}
+
+// clearStops cancels every stop, VIP target and parking move, and turns
+// away the passengers waiting for the car.
+func (e *Elevator) clearStops() {
+	for i := range e.cabUpStops {
+		e.cabUpStops[i], e.cabDownStops[i] = false, false
+		e.hallUpStops[i], e.hallDownStops[i] = false, false
+	}
+	e.minRequest, e.maxRequest = e.MaxFloor+1, e.MinFloor-1
+	e.vipQueue = vipQueue{}
+	e.parking = false
+	e.turnAway()
+}
+
+// runToRecall heads the car for its recall floor, or parks it there if it
+// is already at it. A door open elsewhere closes on the next step. A car
+// stopped for an emergency waits for ResetEmergency.
+func (e *Elevator) runToRecall() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch {
+	case e.CurrentFloor == e.recallFloor:
+		e.parkRecalled()
+	case e.State.doorOpen():
+		e.State, e.doorTimer = StateDoorOpen, 1
+	case e.recallFloor > e.CurrentFloor:
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	default:
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+}
+
+// parkRecalled lets the riders out at the recall floor and holds the door open.
+func (e *Elevator) parkRecalled() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateFireService
+}
+
+// resumeService returns a car leaving fire service to normal operation: a
+// held door re-times and closes as usual, a moving car with no stops left
+// comes to rest. A car stopped for an emergency waits for ResetEmergency.
+func (e *Elevator) resumeService() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch e.State {
+	case StateFireService:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	case StateMovingUp, StateMovingDown:
+		e.pickDirection()
+	}
+}
+
+// SeismicStop stops the car for an earthquake: every stop is cancelled and
+// waiting passengers are turned away. A moving car stops at the next floor
+// in its direction; a standing car opens its door where it is. Either way it
+// lets its riders out and stays there, door open, until ResetEmergency.
+func (e *Elevator) SeismicStop() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	e.mode = EmergencySeismic
+	e.clearStops()
+	if e.State != StateMovingUp && e.State != StateMovingDown {
+		e.emergencyStop()
+	}
+}
+
+// PowerFailure stops the car where it is on loss of normal power: every stop
+// is cancelled and waiting passengers are turned away. A car with its door
+// open lets its riders out there. Any other car is stranded with its riders
+// until LowerToFloor: a moving car between its floor and the next, a
+// standing one with its door shut.
+func (e *Elevator) PowerFailure() {
+	if e.mode == EmergencyPowerFailure {
+		return
+	}
+	e.mode = EmergencyPowerFailure
+	if e.State == StateEmergencyStop {
+		return
+	}
+	e.clearStops()
+	switch {
+	case e.State.doorOpen():
+		e.emergencyStop()
+		return
+	case e.State == StateMovingDown:
+		e.lowerTo = e.CurrentFloor - 1
+	default:
+		e.lowerTo = e.CurrentFloor
+	}
+	e.State = StateStranded
+}
+
+// LowerToFloor runs a stranded car on emergency power down to the nearest
+// floor, where it opens its door and lets its riders out. Returns false if
+// the car is not stranded.
+func (e *Elevator) LowerToFloor() bool {
+	if e.State != StateStranded {
+		return false
+	}
+	e.State = StateLowering
+	if e.lowerTo < e.CurrentFloor {
+		e.Direction = DirDown
+	}
+	return true
+}
+
+// ResetEmergency puts the car back into service where it stands once the
+// building is safe or power is back. Riders still on board re-register their
+// cab calls, unless a fire recall is in force.
+func (e *Elevator) ResetEmergency() {
+	if e.mode == EmergencyNone {
+		return
+	}
+	e.mode = EmergencyNone
+	if e.State == StateEmergencyStop {
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return
+	}
+	e.State = StateIdle
+	if !e.recalling() {
+		for _, p := range e.occupants {
+			e.addRequest(p.CabRequest())
+		}
+	}
+	e.pickDirection()
+}
+
+// emergencyStop opens the door where the car stands, lets the riders out
+// and takes the car out of service.
+func (e *Elevator) emergencyStop() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateEmergencyStop
+}
+
+func (e *Elevator) stopParking() {
+	e.parking = false
+	e.Direction = DirIdle
+	e.State = StateIdle
+}
+
+// CancelRequest removes the stop r set: for a cab call the cab stop at
+// r.Floor, for a hall call the hall stop at r.Floor in r.Direction. A cab
+// stop a rider on board is bound for, or a hall stop passengers are waiting
+// at, is kept. A moving car with nothing left ahead re-runs LOOK. Returns
+// whether a stop was removed.
+func (e *Elevator) CancelRequest(r Request) bool {
+	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor || !e.cancellable(r) {
+		return false
+	}
+	i := e.idx(r.Floor)
+	var had bool
+	switch {
+	case r.Type == CabCall:
+		had = e.cabUpStops[i] || e.cabDownStops[i]
+		e.cabUpStops[i], e.cabDownStops[i] = false, false
+	case r.Direction == DirUp:
+		had = e.hallUpStops[i]
+		e.hallUpStops[i] = false
+	default:
+		had = e.hallDownStops[i]
+		e.hallDownStops[i] = false
+	}
+	if !had {
+		return false
+	}
+	if r.Floor == e.minRequest || r.Floor == e.maxRequest {
+		e.recalcBounds()
+	}
+	if !e.hasStopAt(i) {
+		e.dropVIP(r.Floor)
+	}
+	e.reconsiderDirection()
+	return true
+}
+
+// PressCabButton is a floor button in the cab. Pressing an unlit button
+// registers a cab call as AddRequest does; pressing a lit one again cancels
+// the call (see CancelRequest), so a mis-press can be undone.
+func (e *Elevator) PressCabButton(floor int) error {
+	if e.CancelRequest(Request{Floor: floor, Type: CabCall}) {
+		return nil
+	}
+	return e.AddRequest(Request{Floor: floor, Type: CabCall})
+}
+
+// ReleaseHallCall drops the hall stop at floor in direction dir and returns
+// the passengers waiting for it, so another car can take over the call.
+func (e *Elevator) ReleaseHallCall(floor int, dir Direction) []*Passenger {
+	if floor < e.MinFloor || floor > e.MaxFloor {
+		return nil
+	}
+	i := e.idx(floor)
+	if dir == DirUp {
+		e.hallUpStops[i] = false
+	} else {
+		e.hallDownStops[i] = false
+	}
+	if floor == e.minRequest || floor == e.maxRequest {
+		e.recalcBounds()
+	}
+	if !e.hasStopAt(i) {
+		e.dropVIP(floor)
+	}
+	e.reconsiderDirection()
+	return e.release(floor, dir)
+}
+
+// reconsiderDirection re-runs the direction choice of a moving car after its
+// stops changed, so a car whose last stop ahead vanished turns around (or
+// serves its own floor) instead of running on past it, a car whose VIP
+// target vanished serves the next VIP stop if it is at that floor, and a car
+// with a new VIP stop behind it turns toward that stop.
+func (e *Elevator) reconsiderDirection() {
+	if e.parking || (e.State != StateMovingUp && e.State != StateMovingDown) {
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f == e.CurrentFloor {
+		// The VIP stop being run to went away; the next one is here.
+		e.opening = true
+		e.openDoor(DirIdle)
+		return
+	}
+	e.pickDirection()
+	if e.State == StateIdle && e.HasPendingRequests() {
+		e.opening = true
+		e.openDoor(DirIdle)
+	}
 }
 
 // Step advances the elevator by one time unit.
 // Returns a human-readable description of what happened.
 func (e *Elevator) Step() string {
+	return FormatStep(e.StepEvents())
+}
+
+// StepEvents advances the elevator by one time unit and returns what happened.
+// A door the car opened between steps, for a call at its own floor, is
+// reported first: StepDoorOpened comes ahead of the step's own events.
+func (e *Elevator) StepEvents() []StepEvent {
+	var opened []StepEvent
+	if e.opening {
+		e.opening = false
+		opened = []StepEvent{e.event(StepDoorOpened)}
+	}
+	return append(opened, e.step()...)
+}
+
+// step runs the car's state machine for one time unit.
+func (e *Elevator) step() []StepEvent {
+	if e.stalls(e.State, e.doorTimer) {
+		ev := e.event(StepFault)
+		ev.Fault = e.fault
+		return []StepEvent{ev}
+	}
+	dir := e.Direction
+	var evs []StepEvent
 	switch e.State {
 	case StateDoorOpen:
-		return e.stepDoorOpen()
+		evs = e.stepDoorOpen()
+	case StateDoorHold:
+		evs = e.stepDoorHold()
+	case StateNudging:
+		evs = e.stepNudging()
+	case StateOverloaded:
+		evs = e.stepOverloaded()
+	case StateFireService:
+		evs = e.stepFireService()
+	case StateStranded:
+		evs = []StepEvent{e.event(StepStranded)}
+	case StateLowering:
+		evs = e.stepLowering()
+	case StateEmergencyStop:
+		evs = []StepEvent{e.event(StepOutOfService)}
 	case StateMovingUp:
-		return e.stepMove(DirUp)
+		evs = e.stepMove(DirUp)
 	case StateMovingDown:
-		return e.stepMove(DirDown)
+		evs = e.stepMove(DirDown)
 	default: // StateIdle
-		return e.stepIdle()
+		evs = e.stepIdle()
+	}
+	if e.Direction != dir {
+		evs = append(evs, e.event(StepDirectionChanged))
+	}
+	return evs
+}
+
+// event returns a StepEvent of the given kind at the car's current position.
+func (e *Elevator) event(kind StepKind) StepEvent {
+	return StepEvent{Kind: kind, CarID: e.ID, Floor: e.CurrentFloor, Direction: e.Direction}
+}
+
+func (e *Elevator) stepDoorOpen() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		ev := e.event(StepDoorHeld)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
 	}
+	return e.closeDoor()
 }
 
-func (e *Elevator) stepDoorOpen() string {
+// stepDoorHold keeps the door open for the door-open-hold button until the
+// hold runs out, then closes it as at the end of a dwell.
+func (e *Elevator) stepDoorHold() []StepEvent {
 	e.doorTimer--
 	if e.doorTimer > 0 {
-		return fmt.Sprintf("Elevator %d: door open at floor %d (closing in %d)",
-			e.ID, e.CurrentFloor, e.doorTimer)
+		ev := e.event(StepHoldOpen)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
 	}
-	// Door closes — decide next action.
+	return e.closeDoor()
+}
+
+// closeDoor closes the door at the end of its dwell and decides the next
+// action. A blocked obstruction sensor reopens the door for another dwell
+// instead; after DoorConfig.NudgeAfter reopens in a row the door nudges.
+func (e *Elevator) closeDoor() []StepEvent {
+	if e.obstructed {
+		if e.reopen() {
+			e.State, e.doorTimer = StateNudging, nudgeSteps
+			return []StepEvent{e.event(StepNudging)}
+		}
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return []StepEvent{e.event(StepDoorReopened)}
+	}
+	return e.shutDoor()
+}
+
+// stepNudging closes the door slowly with the buzzer sounding. The
+// obstruction sensor no longer reopens it.
+func (e *Elevator) stepNudging() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		return []StepEvent{e.event(StepNudging)}
+	}
+	return e.shutDoor()
+}
+
+// shutDoor closes the door and decides the next action.
+func (e *Elevator) shutDoor() []StepEvent {
+	e.reopens = 0
 	e.State = StateIdle
+	if e.antiNuisance && len(e.occupants) == 0 && e.terminal(e.CurrentFloor) {
+		// Anti-nuisance: nobody is on board to have pressed the cab buttons left.
+		cancelCabCalls(e)
+	}
 	e.pickDirection()
-	return fmt.Sprintf("Elevator %d: door closed at floor %d, direction=%s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return []StepEvent{e.event(StepDoorClosed)}
 }
 
-func (e *Elevator) stepMove(dir Direction) string {
+// stepOverloaded sounds the overload alarm with the door held open. Each step
+// the last passenger to board here steps back off and waits for the car's
+// next pass; once the car is within capacity the door re-times and closes
+// as usual.
+func (e *Elevator) stepOverloaded() []StepEvent {
+	p := e.stepOff(e.CurrentFloor)
+	if p != nil {
+		e.addRequest(p.HallRequest())
+	}
+	if p == nil || !e.overloaded() {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
+	return []StepEvent{e.event(StepOverloadAlarm)}
+}
+
+// stepLowering runs a car on emergency power one floor down toward its
+// lowering floor and stops there, or opens the door if it is already there.
+func (e *Elevator) stepLowering() []StepEvent {
+	var evs []StepEvent
+	if e.CurrentFloor > e.lowerTo {
+		e.CurrentFloor--
+		evs = append(evs, e.event(StepMoved))
+		if e.CurrentFloor > e.lowerTo {
+			return evs
+		}
+	}
+	e.emergencyStop()
+	return append(evs, e.event(StepEmergencyStop))
+}
+
+// stepFireService holds the door open in fire service. Under firefighter
+// control (Phase II) the door closes once a cab call is registered and the
+// car sets off for it.
+func (e *Elevator) stepFireService() []StepEvent {
+	if !e.firefighter || !e.HasPendingRequests() {
+		return []StepEvent{e.event(StepFireService)}
+	}
+	e.State = StateIdle
+	e.pickDirection()
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+func (e *Elevator) stepMove(dir Direction) []StepEvent {
 	// Move one floor.
 	if dir == DirUp {
 		e.CurrentFloor++
@@ -149,24 +714,62 @@ func (e *Elevator) stepMove(dir Direction) string {
 		e.CurrentFloor--
 	}
 
-	msg := fmt.Sprintf("Elevator %d: moved to floor %d", e.ID, e.CurrentFloor)
+	evs := []StepEvent{e.event(StepMoved)}
+	if e.mode == EmergencySeismic {
+		// Seismic stop at the first floor reached.
+		e.emergencyStop()
+		return append(evs, e.event(StepEmergencyStop))
+	}
+	if e.recalling() {
+		// Fire recall: run non-stop to the recall floor.
+		if e.CurrentFloor == e.recallFloor {
+			e.parkRecalled()
+			evs = append(evs, e.event(StepRecalled))
+		}
+		return evs
+	}
+	if e.parking {
+		if e.CurrentFloor == e.parkFloor {
+			e.stopParking()
+			evs = append(evs, e.event(StepParked))
+		}
+		return evs
+	}
+
+	// VIP express: run non-stop to the VIP floor and serve it in both directions.
+	if f, ok := e.vipTarget(); ok {
+		if e.CurrentFloor == f {
+			stop := e.event(StepStopped)
+			stop.VIP = true
+			e.openDoor(DirIdle)
+			evs = append(evs, stop, e.event(StepDoorOpened))
+		}
+		return evs
+	}
 
 	// Check if we should stop here.
 	if e.shouldStop(dir) {
+		evs = append(evs, e.event(StepStopped))
 		e.openDoor(dir)
-		msg += " [STOP — door opening]"
+		evs = append(evs, e.event(StepDoorOpened))
+	} else if e.WeightSensor() && e.hallStopAt(dir) {
+		evs = append(evs, e.event(StepOverloaded))
 	}
-	return msg
+	return evs
 }
 
-func (e *Elevator) stepIdle() string {
+func (e *Elevator) stepIdle() []StepEvent {
 	e.pickDirection()
-	if e.State == StateIdle {
-		return fmt.Sprintf("Elevator %d: idle at floor %d", e.ID, e.CurrentFloor)
+	return []StepEvent{e.event(StepIdle)}
+}
+
+// hallStopAt reports whether the current floor has a hall stop in direction dir.
+func (e *Elevator) hallStopAt(dir Direction) bool {
+	i := e.idx(e.CurrentFloor)
+	if dir == DirUp {
+		return e.hallUpStops[i]
 	}
-	// Started moving — delegate to move step next tick.
-	return fmt.Sprintf("Elevator %d: idle at floor %d, starting %s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return e.hallDownStops[i]
 }
 
 // shouldStop reports whether the elevator should stop at the current floor.
@@ -196,7 +799,8 @@ func (e *Elevator) shouldStop(dir Direction) bool {
 	return !e.hasStopsBelow() && (e.cabUpStops[i] || e.hallUpStops[i])
 }
 
-// openDoor transitions to door-open state and clears the served stops.
+// openDoor transitions to door-open state, clears the served stops and
+// exchanges passengers.
 //
 // Direction-aware clearing:
 //   - DirUp:   clear upStops (intermediate stop serving up passengers)
@@ -206,63 +810,48 @@ func (e *Elevator) shouldStop(dir Direction) bool {
 // Turnaround: when no more stops ahead in the current direction,
 // also clear the opposite direction's stop (the elevator is reversing
 // and won't revisit this floor in the new direction).
+//
+// Riders for this floor alight, then waiting passengers travelling in a
+// served direction board and register their cab calls. If that puts the car
+// over capacity it sounds the overload alarm instead (see stepOverloaded).
+//
+// A door opening at a VIP floor serves that VIP stop; any other opening
+// ends the current VIP streak.
 func (e *Elevator) openDoor(dir Direction) {
-	e.State = StateDoorOpen
-	e.doorTimer = doorOpenSteps
-	i := e.idx(e.CurrentFloor)
-
-	if dir == DirUp || dir == DirIdle {
-		if e.cabUpStops[i] {
-			e.cabUpStops[i] = false
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallUpStops[i] {
-			e.hallUpStops[i] = false
-			e.currentWeight += passengerWeight
-		}
-	}
-	if dir == DirDown || dir == DirIdle {
-		if e.cabDownStops[i] {
-			e.cabDownStops[i] = false
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallDownStops[i] {
-			e.hallDownStops[i] = false
-			e.currentWeight += passengerWeight
-		}
+	if e.State != StateDoorHold {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
 	}
+	i := e.idx(e.CurrentFloor)
 
-	// Turnaround: also clear opposite direction stop.
-	if dir == DirUp && !e.hasStopsAbove() {
-		if e.cabDownStops[i] {
-			e.cabDownStops[i] = false
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallDownStops[i] {
-			e.hallDownStops[i] = false
-			e.currentWeight += passengerWeight
-		}
+	up := dir == DirUp || dir == DirIdle || (dir == DirDown && !e.hasStopsBelow())
+	down := dir == DirDown || dir == DirIdle || (dir == DirUp && !e.hasStopsAbove())
+	if up {
+		e.cabUpStops[i] = false
+		e.hallUpStops[i] = false
 	}
-	if dir == DirDown && !e.hasStopsBelow() {
-		if e.cabUpStops[i] {
-			e.cabUpStops[i] = false
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallUpStops[i] {
-			e.hallUpStops[i] = false
-			e.currentWeight += passengerWeight
-		}
+	if down {
+		e.cabDownStops[i] = false
+		e.hallDownStops[i] = false
 	}
 
-	// Clamp weight to non-negative.
-	if e.currentWeight < 0 {
-		e.currentWeight = 0
+	e.alight(e.CurrentFloor)
+	for _, p := range e.board(e.CurrentFloor, up, down) {
+		e.addRequest(p.CabRequest())
 	}
+	e.vipServed(e.CurrentFloor)
 
 	// Recalculate bounds only if we just removed a boundary floor.
 	if e.CurrentFloor == e.minRequest || e.CurrentFloor == e.maxRequest {
 		e.recalcBounds()
 	}
+
+	if e.firefighter {
+		// Phase II: the door stays open until the next cab call.
+		e.State = StateFireService
+	} else if e.overloaded() {
+		e.State = StateOverloaded
+	}
 }
 
 // recalcBounds rescans upStops and downStops to find new min/max.
@@ -283,7 +872,23 @@ func (e *Elevator) recalcBounds() {
 }
 
 // pickDirection decides the next direction based on pending requests (LOOK algorithm).
+// A fire recall overrides everything; then a pending VIP stop comes first:
+// the car heads straight for it.
 func (e *Elevator) pickDirection() {
+	if e.recalling() {
+		e.runToRecall()
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f != e.CurrentFloor {
+		if f > e.CurrentFloor {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+		} else {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+		}
+		return
+	}
 	switch e.Direction {
 	case DirUp:
 		if e.hasStopsAbove() {
@@ -323,6 +928,11 @@ func (e *Elevator) pickDirection() {
 	e.State = StateIdle
 }
 
+// hasStopAt reports whether any stop set holds index i.
+func (e *Elevator) hasStopAt(i int) bool {
+	return e.cabUpStops[i] || e.cabDownStops[i] || e.hallUpStops[i] || e.hallDownStops[i]
+}
+
 // hasStopsAbove — O(1): compare current floor with cached maxRequest.
 func (e *Elevator) hasStopsAbove() bool {
 	return e.maxRequest > e.CurrentFloor
@@ -383,8 +993,3 @@ func (e *Elevator) StopsHallSnapshot() (up []int, down []int) {
 	}
 	return
 }
-
-// WeightSensor check is overweight
-func (e *Elevator) WeightSensor() bool {
-	return e.currentWeight >= e.maxWeight
-}
@@ -31,8 +31,21 @@ type BitmaskElevator struct {
 
 	doorTimer int
 
-	currentWeight int
-	maxWeight     int
+	// maintenance: finish current stops, accept no new requests.
+	maintenance bool
+
+	// parking: moving to parkFloor with no request, dropped on the first real one.
+	parking   bool
+	parkFloor int
+
+	cabin
+	vipQueue
+	motion
+	zone
+	fireService
+	emergency
+	faultState
+	door
 }
 
 const bitmaskMaxFloors = 64
@@ -50,10 +63,25 @@ func NewBitmaskElevator(id, minFloor, maxFloor int) *BitmaskElevator {
 		Direction:    DirIdle,
 		MinFloor:     minFloor,
 		MaxFloor:     maxFloor,
-		maxWeight:    100,
+		cabin:        newCabin(),
+		door:         newDoor(),
+		motion:       newMotion(),
+		zone:         newZone(minFloor, maxFloor),
 	}
 }
 
+// CarID returns the elevator's identifier.
+func (e *BitmaskElevator) CarID() int { return e.ID }
+
+// Floor returns the floor the elevator is currently at.
+func (e *BitmaskElevator) Floor() int { return e.CurrentFloor }
+
+// CurrentState returns the elevator's state.
+func (e *BitmaskElevator) CurrentState() ElevatorState { return e.State }
+
+// CurrentDirection returns the elevator's travel direction.
+func (e *BitmaskElevator) CurrentDirection() Direction { return e.Direction }
+
 // --- Bit manipulation helpers ---
 
 // idx converts a floor number to the bit position.
@@ -92,13 +120,35 @@ func belowMask(bit uint) uint64 {
 
 // --- Core elevator logic (same LOOK algorithm, different data structure) ---
 
-func (e *BitmaskElevator) AddRequest(r Request) {
-	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor {
-		return
+func (e *BitmaskElevator) AddRequest(r Request) error {
+	if e.mode != EmergencyNone {
+		return ErrOutOfService
+	}
+	if e.refuses(r) {
+		return ErrFireService
+	}
+	if e.maintenance {
+		return ErrInMaintenance
 	}
-	if r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State == StateDoorOpen) {
+	return e.addRequest(r)
+}
+
+func (e *BitmaskElevator) addRequest(r Request) error {
+	if !e.Serves(r.Floor) {
+		return ErrFloorNotServed
+	}
+	if e.parking {
+		e.stopParking()
+	}
+	atFloor := r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State.loading())
+	vip := r.Priority > PriorityNormal && (atFloor || r.Floor != e.CurrentFloor)
+	if vip {
+		e.pushVIP(r.Floor)
+	}
+	if atFloor {
+		e.opening = e.opening || e.State == StateIdle
 		e.openDoor(DirIdle)
-		return
+		return nil
 	}
 
 	bit := e.idx(r.Floor)
@@ -110,10 +160,14 @@ func (e *BitmaskElevator) AddRequest(r Request) {
 			set(&e.hallDownStops, bit)
 		}
 	case CabCall:
-		if r.Floor > e.CurrentFloor {
+		switch r.cabDirection(e.CurrentFloor) {
+		case DirUp:
 			set(&e.cabUpStops, bit)
-		} else if r.Floor < e.CurrentFloor {
+		case DirDown:
 			set(&e.cabDownStops, bit)
+		default:
+			// The car is leaving this floor: a cab call for it is not latched.
+			return nil
 		}
 	}
 
@@ -126,55 +180,536 @@ func (e *BitmaskElevator) AddRequest(r Request) {
 			e.State = StateMovingDown
 		}
 	}
+	if vip {
+		e.reconsiderDirection()
+	}
+	return nil
+}
+
+// AddPassenger assigns a waiting passenger to this car and places its hall call.
+func (e *BitmaskElevator) AddPassenger(p *Passenger) {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(p.Origin) {
+		return
+	}
+	e.await(p)
+	e.AddRequest(p.HallRequest())
+}
+
+// SetMaintenance takes the elevator out of service or puts it back.
+// In maintenance it finishes the stops it already has but refuses new requests.
+func (e *BitmaskElevator) SetMaintenance(on bool) { e.maintenance = on }
+
+// InMaintenance reports whether the elevator is in maintenance.
+func (e *BitmaskElevator) InMaintenance() bool { return e.maintenance }
+
+// Park sends an idle car to floor to wait for calls there. The move does not
+// open the door and is abandoned as soon as the car gets a real request.
+// Returns false if the car is busy, in maintenance, fire service or an
+// emergency stop, or does not serve floor.
+func (e *BitmaskElevator) Park(floor int) bool {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(floor) ||
+		e.HasPendingRequests() || (e.State != StateIdle && !e.parking) {
+		return false
+	}
+	e.stopParking()
+	switch {
+	case floor > e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	case floor < e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+	return true
+}
+
+// Parking reports whether the car is on its way to a parking floor.
+func (e *BitmaskElevator) Parking() bool { return e.parking }
+
+// HoldDoor is the door-open-hold button; see Elevator.HoldDoor.
+func (e *BitmaskElevator) HoldDoor() bool {
+	switch {
+	case e.State == StateDoorOpen && e.doorConfig.MaxHoldSteps > 0:
+		e.State, e.doorTimer = StateDoorHold, e.doorConfig.MaxHoldSteps
+	case e.State == StateDoorHold:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	default:
+		return false
+	}
+	return true
+}
+
+// CloseDoor is the door-close button; see Elevator.CloseDoor.
+func (e *BitmaskElevator) CloseDoor() bool {
+	if e.State != StateDoorOpen && e.State != StateDoorHold {
+		return false
+	}
+	e.State, e.doorTimer = StateDoorOpen, 1
+	return true
+}
+
+// FireRecall puts the car under Phase I fire recall; see Elevator.FireRecall.
+func (e *BitmaskElevator) FireRecall(floor int) error {
+	if !e.Serves(floor) {
+		return ErrFloorNotServed
+	}
+	e.recall, e.recallFloor = true, floor
+	if e.firefighter {
+		return nil
+	}
+	e.clearStops()
+	e.runToRecall()
+	return nil
+}
+
+// EndFireRecall resets Phase I; see Elevator.EndFireRecall.
+func (e *BitmaskElevator) EndFireRecall() {
+	if !e.recall {
+		return
+	}
+	e.recall = false
+	if !e.firefighter {
+		e.resumeService()
+	}
+}
+
+// SetFirefighterService turns the car's Phase II key switch; see
+// Elevator.SetFirefighterService.
+func (e *BitmaskElevator) SetFirefighterService(on bool) error {
+	if on == e.firefighter {
+		return nil
+	}
+	if on {
+		if !e.recalling() || e.State != StateFireService {
+			return ErrNotRecalled
+		}
+		e.firefighter = true
+		return nil
+	}
+	e.firefighter = false
+	e.clearStops()
+	if e.recall {
+		e.runToRecall()
+	} else {
+		e.resumeService()
+	}
+	return nil
+}
+
+// clearStops cancels every stop, VIP target and parking move, and turns
+// away the passengers waiting for the car.
+func (e *BitmaskElevator) clearStops() {
+	e.cabUpStops, e.cabDownStops, e.hallUpStops, e.hallDownStops = 0, 0, 0, 0
+	e.vipQueue = vipQueue{}
+	e.parking = false
+	e.turnAway()
+}
+
+// runToRecall heads the car for its recall floor, or parks it there.
+func (e *BitmaskElevator) runToRecall() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch {
+	case e.CurrentFloor == e.recallFloor:
+		e.parkRecalled()
+	case e.State.doorOpen():
+		e.State, e.doorTimer = StateDoorOpen, 1
+	case e.recallFloor > e.CurrentFloor:
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	default:
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+}
+
+func (e *BitmaskElevator) parkRecalled() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateFireService
+}
+
+func (e *BitmaskElevator) resumeService() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch e.State {
+	case StateFireService:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	case StateMovingUp, StateMovingDown:
+		e.pickDirection()
+	}
+}
+
+// SeismicStop stops the car for an earthquake; see Elevator.SeismicStop.
+func (e *BitmaskElevator) SeismicStop() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	e.mode = EmergencySeismic
+	e.clearStops()
+	if e.State != StateMovingUp && e.State != StateMovingDown {
+		e.emergencyStop()
+	}
+}
+
+// PowerFailure stops the car where it is; see Elevator.PowerFailure.
+func (e *BitmaskElevator) PowerFailure() {
+	if e.mode == EmergencyPowerFailure {
+		return
+	}
+	e.mode = EmergencyPowerFailure
+	if e.State == StateEmergencyStop {
+		return
+	}
+	e.clearStops()
+	switch {
+	case e.State.doorOpen():
+		e.emergencyStop()
+		return
+	case e.State == StateMovingDown:
+		e.lowerTo = e.CurrentFloor - 1
+	default:
+		e.lowerTo = e.CurrentFloor
+	}
+	e.State = StateStranded
+}
+
+// LowerToFloor runs a stranded car on emergency power to the nearest floor;
+// see Elevator.LowerToFloor.
+func (e *BitmaskElevator) LowerToFloor() bool {
+	if e.State != StateStranded {
+		return false
+	}
+	e.State = StateLowering
+	if e.lowerTo < e.CurrentFloor {
+		e.Direction = DirDown
+	}
+	return true
+}
+
+// ResetEmergency puts the car back into service; see Elevator.ResetEmergency.
+func (e *BitmaskElevator) ResetEmergency() {
+	if e.mode == EmergencyNone {
+		return
+	}
+	e.mode = EmergencyNone
+	if e.State == StateEmergencyStop {
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return
+	}
+	e.State = StateIdle
+	if !e.recalling() {
+		for _, p := range e.occupants {
+			e.addRequest(p.CabRequest())
+		}
+	}
+	e.pickDirection()
+}
+
+func (e *BitmaskElevator) emergencyStop() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateEmergencyStop
+}
+
+func (e *BitmaskElevator) stopParking() {
+	e.parking = false
+	e.Direction = DirIdle
+	e.State = StateIdle
+}
+
+// CancelRequest removes the stop r set; see Elevator.CancelRequest.
+func (e *BitmaskElevator) CancelRequest(r Request) bool {
+	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor || !e.cancellable(r) {
+		return false
+	}
+	bit := e.idx(r.Floor)
+	var had bool
+	switch {
+	case r.Type == CabCall:
+		had = has(e.cabUpStops|e.cabDownStops, bit)
+		clear(&e.cabUpStops, bit)
+		clear(&e.cabDownStops, bit)
+	case r.Direction == DirUp:
+		had = has(e.hallUpStops, bit)
+		clear(&e.hallUpStops, bit)
+	default:
+		had = has(e.hallDownStops, bit)
+		clear(&e.hallDownStops, bit)
+	}
+	if !had {
+		return false
+	}
+	if !e.hasStopAt(bit) {
+		e.dropVIP(r.Floor)
+	}
+	e.reconsiderDirection()
+	return true
+}
+
+// PressCabButton is a floor button in the cab; see Elevator.PressCabButton.
+func (e *BitmaskElevator) PressCabButton(floor int) error {
+	if e.CancelRequest(Request{Floor: floor, Type: CabCall}) {
+		return nil
+	}
+	return e.AddRequest(Request{Floor: floor, Type: CabCall})
+}
+
+// ReleaseHallCall drops the hall stop at floor in direction dir and returns
+// the passengers waiting for it, so another car can take over the call.
+func (e *BitmaskElevator) ReleaseHallCall(floor int, dir Direction) []*Passenger {
+	if floor < e.MinFloor || floor > e.MaxFloor {
+		return nil
+	}
+	if dir == DirUp {
+		clear(&e.hallUpStops, e.idx(floor))
+	} else {
+		clear(&e.hallDownStops, e.idx(floor))
+	}
+	if !e.hasStopAt(e.idx(floor)) {
+		e.dropVIP(floor)
+	}
+	e.reconsiderDirection()
+	return e.release(floor, dir)
+}
+
+// reconsiderDirection re-runs the direction choice of a moving car after its
+// stops changed.
+func (e *BitmaskElevator) reconsiderDirection() {
+	if e.parking || (e.State != StateMovingUp && e.State != StateMovingDown) {
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f == e.CurrentFloor {
+		// The VIP stop being run to went away; the next one is here.
+		e.opening = true
+		e.openDoor(DirIdle)
+		return
+	}
+	e.pickDirection()
+	if e.State == StateIdle && e.HasPendingRequests() {
+		e.opening = true
+		e.openDoor(DirIdle)
+	}
 }
 
 func (e *BitmaskElevator) Step() string {
+	return FormatStep(e.StepEvents())
+}
+
+// StepEvents advances the elevator by one time unit and returns what happened.
+func (e *BitmaskElevator) StepEvents() []StepEvent {
+	var opened []StepEvent
+	if e.opening {
+		e.opening = false
+		opened = []StepEvent{e.event(StepDoorOpened)}
+	}
+	return append(opened, e.step()...)
+}
+
+// step runs the car's state machine for one time unit.
+func (e *BitmaskElevator) step() []StepEvent {
+	if e.stalls(e.State, e.doorTimer) {
+		ev := e.event(StepFault)
+		ev.Fault = e.fault
+		return []StepEvent{ev}
+	}
+	dir := e.Direction
+	var evs []StepEvent
 	switch e.State {
 	case StateDoorOpen:
-		return e.stepDoorOpen()
+		evs = e.stepDoorOpen()
+	case StateDoorHold:
+		evs = e.stepDoorHold()
+	case StateNudging:
+		evs = e.stepNudging()
+	case StateOverloaded:
+		evs = e.stepOverloaded()
+	case StateFireService:
+		evs = e.stepFireService()
+	case StateStranded:
+		evs = []StepEvent{e.event(StepStranded)}
+	case StateLowering:
+		evs = e.stepLowering()
+	case StateEmergencyStop:
+		evs = []StepEvent{e.event(StepOutOfService)}
 	case StateMovingUp:
-		return e.stepMove(DirUp)
+		evs = e.stepMove(DirUp)
 	case StateMovingDown:
-		return e.stepMove(DirDown)
+		evs = e.stepMove(DirDown)
 	default:
-		return e.stepIdle()
+		evs = e.stepIdle()
+	}
+	if e.Direction != dir {
+		evs = append(evs, e.event(StepDirectionChanged))
+	}
+	return evs
+}
+
+// event returns a StepEvent of the given kind at the car's current position.
+func (e *BitmaskElevator) event(kind StepKind) StepEvent {
+	return StepEvent{Kind: kind, CarID: e.ID, Floor: e.CurrentFloor, Direction: e.Direction}
+}
+
+func (e *BitmaskElevator) stepDoorOpen() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		ev := e.event(StepDoorHeld)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
 	}
+	return e.closeDoor()
 }
 
-func (e *BitmaskElevator) stepDoorOpen() string {
+// stepDoorHold keeps the door open for the hold button; see Elevator.stepDoorHold.
+func (e *BitmaskElevator) stepDoorHold() []StepEvent {
 	e.doorTimer--
 	if e.doorTimer > 0 {
-		return fmt.Sprintf("Elevator %d: door open at floor %d (closing in %d)",
-			e.ID, e.CurrentFloor, e.doorTimer)
+		ev := e.event(StepHoldOpen)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
 	}
+	return e.closeDoor()
+}
+
+// closeDoor closes the door unless the obstruction sensor reopens it; see
+// Elevator.closeDoor.
+func (e *BitmaskElevator) closeDoor() []StepEvent {
+	if e.obstructed {
+		if e.reopen() {
+			e.State, e.doorTimer = StateNudging, nudgeSteps
+			return []StepEvent{e.event(StepNudging)}
+		}
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return []StepEvent{e.event(StepDoorReopened)}
+	}
+	return e.shutDoor()
+}
+
+// stepNudging closes the door slowly, ignoring the obstruction sensor.
+func (e *BitmaskElevator) stepNudging() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		return []StepEvent{e.event(StepNudging)}
+	}
+	return e.shutDoor()
+}
+
+// shutDoor closes the door and decides the next action.
+func (e *BitmaskElevator) shutDoor() []StepEvent {
+	e.reopens = 0
 	e.State = StateIdle
+	if e.antiNuisance && len(e.occupants) == 0 && e.terminal(e.CurrentFloor) {
+		cancelCabCalls(e)
+	}
 	e.pickDirection()
-	return fmt.Sprintf("Elevator %d: door closed at floor %d, direction=%s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return []StepEvent{e.event(StepDoorClosed)}
 }
 
-func (e *BitmaskElevator) stepMove(dir Direction) string {
+// stepOverloaded sounds the overload alarm with the door held open. Each step
+// the last passenger to board here steps back off and waits for the car's
+// next pass; once the car is within capacity the door re-times and closes
+// as usual.
+func (e *BitmaskElevator) stepOverloaded() []StepEvent {
+	p := e.stepOff(e.CurrentFloor)
+	if p != nil {
+		e.addRequest(p.HallRequest())
+	}
+	if p == nil || !e.overloaded() {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
+	return []StepEvent{e.event(StepOverloadAlarm)}
+}
+
+// stepLowering runs a car on emergency power down to its lowering floor.
+func (e *BitmaskElevator) stepLowering() []StepEvent {
+	var evs []StepEvent
+	if e.CurrentFloor > e.lowerTo {
+		e.CurrentFloor--
+		evs = append(evs, e.event(StepMoved))
+		if e.CurrentFloor > e.lowerTo {
+			return evs
+		}
+	}
+	e.emergencyStop()
+	return append(evs, e.event(StepEmergencyStop))
+}
+
+// stepFireService holds the door open in fire service until a Phase II cab call.
+func (e *BitmaskElevator) stepFireService() []StepEvent {
+	if !e.firefighter || !e.HasPendingRequests() {
+		return []StepEvent{e.event(StepFireService)}
+	}
+	e.State = StateIdle
+	e.pickDirection()
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+func (e *BitmaskElevator) stepMove(dir Direction) []StepEvent {
 	if dir == DirUp {
 		e.CurrentFloor++
 	} else {
 		e.CurrentFloor--
 	}
 
-	msg := fmt.Sprintf("Elevator %d: moved to floor %d", e.ID, e.CurrentFloor)
+	evs := []StepEvent{e.event(StepMoved)}
+	if e.mode == EmergencySeismic {
+		// Seismic stop at the first floor reached.
+		e.emergencyStop()
+		return append(evs, e.event(StepEmergencyStop))
+	}
+	if e.recalling() {
+		// Fire recall: run non-stop to the recall floor.
+		if e.CurrentFloor == e.recallFloor {
+			e.parkRecalled()
+			evs = append(evs, e.event(StepRecalled))
+		}
+		return evs
+	}
+	if e.parking {
+		if e.CurrentFloor == e.parkFloor {
+			e.stopParking()
+			evs = append(evs, e.event(StepParked))
+		}
+		return evs
+	}
+
+	if f, ok := e.vipTarget(); ok {
+		if e.CurrentFloor == f {
+			stop := e.event(StepStopped)
+			stop.VIP = true
+			e.openDoor(DirIdle)
+			evs = append(evs, stop, e.event(StepDoorOpened))
+		}
+		return evs
+	}
+
 	if e.shouldStop(dir) {
+		evs = append(evs, e.event(StepStopped))
 		e.openDoor(dir)
-		msg += " [STOP — door opening]"
+		evs = append(evs, e.event(StepDoorOpened))
+	} else if e.WeightSensor() && e.hallStopAt(dir) {
+		evs = append(evs, e.event(StepOverloaded))
 	}
-	return msg
+	return evs
 }
 
-func (e *BitmaskElevator) stepIdle() string {
+func (e *BitmaskElevator) stepIdle() []StepEvent {
 	e.pickDirection()
-	if e.State == StateIdle {
-		return fmt.Sprintf("Elevator %d: idle at floor %d", e.ID, e.CurrentFloor)
+	return []StepEvent{e.event(StepIdle)}
+}
+
+// hallStopAt reports whether the current floor has a hall stop in direction dir.
+func (e *BitmaskElevator) hallStopAt(dir Direction) bool {
+	bit := e.idx(e.CurrentFloor)
+	if dir == DirUp {
+		return has(e.hallUpStops, bit)
 	}
-	return fmt.Sprintf("Elevator %d: idle at floor %d, starting %s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return has(e.hallDownStops, bit)
 }
 
 // shouldStop — O(1) with bitmask operations.
@@ -209,60 +744,52 @@ func (e *BitmaskElevator) shouldStop(dir Direction) bool {
 }
 
 func (e *BitmaskElevator) openDoor(dir Direction) {
-	e.State = StateDoorOpen
-	e.doorTimer = doorOpenSteps
+	if e.State != StateDoorHold {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
 	bit := e.idx(e.CurrentFloor)
 
-	if dir == DirUp || dir == DirIdle {
-		if has(e.cabUpStops, bit) {
-			clear(&e.cabUpStops, bit)
-			e.currentWeight -= passengerWeight
-		}
-		if has(e.hallUpStops, bit) {
-			clear(&e.hallUpStops, bit)
-			e.currentWeight += passengerWeight
-		}
+	up := dir == DirUp || dir == DirIdle || (dir == DirDown && !e.hasStopsBelow())
+	down := dir == DirDown || dir == DirIdle || (dir == DirUp && !e.hasStopsAbove())
+	if up {
+		clear(&e.cabUpStops, bit)
+		clear(&e.hallUpStops, bit)
 	}
-	if dir == DirDown || dir == DirIdle {
-		if has(e.cabDownStops, bit) {
-			clear(&e.cabDownStops, bit)
-			e.currentWeight -= passengerWeight
-		}
-		if has(e.hallDownStops, bit) {
-			clear(&e.hallDownStops, bit)
-			e.currentWeight += passengerWeight
-		}
+	if down {
+		clear(&e.cabDownStops, bit)
+		clear(&e.hallDownStops, bit)
 	}
 
-	// Turnaround: also clear opposite direction stop.
-	if dir == DirUp && !e.hasStopsAbove() {
-		if has(e.cabDownStops, bit) {
-			clear(&e.cabDownStops, bit)
-			e.currentWeight -= passengerWeight
-		}
-		if has(e.hallDownStops, bit) {
-			clear(&e.hallDownStops, bit)
-			e.currentWeight += passengerWeight
-		}
-	}
-	if dir == DirDown && !e.hasStopsBelow() {
-		if has(e.cabUpStops, bit) {
-			clear(&e.cabUpStops, bit)
-			e.currentWeight -= passengerWeight
-		}
-		if has(e.hallUpStops, bit) {
-			clear(&e.hallUpStops, bit)
-			e.currentWeight += passengerWeight
-		}
+	e.alight(e.CurrentFloor)
+	for _, p := range e.board(e.CurrentFloor, up, down) {
+		e.addRequest(p.CabRequest())
 	}
+	e.vipServed(e.CurrentFloor)
 
-	// Clamp weight to non-negative.
-	if e.currentWeight < 0 {
-		e.currentWeight = 0
+	if e.firefighter {
+		// Phase II: the door stays open until the next cab call.
+		e.State = StateFireService
+	} else if e.overloaded() {
+		e.State = StateOverloaded
 	}
 }
 
 func (e *BitmaskElevator) pickDirection() {
+	if e.recalling() {
+		e.runToRecall()
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f != e.CurrentFloor {
+		if f > e.CurrentFloor {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+		} else {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+		}
+		return
+	}
 	switch e.Direction {
 	case DirUp:
 		if e.hasStopsAbove() {
@@ -300,6 +827,11 @@ func (e *BitmaskElevator) pickDirection() {
 	e.State = StateIdle
 }
 
+// hasStopAt — O(1): check bit in the union of all stop sets.
+func (e *BitmaskElevator) hasStopAt(bit uint) bool {
+	return has(e.cabUpStops|e.cabDownStops|e.hallUpStops|e.hallDownStops, bit)
+}
+
 // hasStopsAbove — O(1): mask off bits above current floor, check != 0.
 func (e *BitmaskElevator) hasStopsAbove() bool {
 	mask := aboveMask(e.idx(e.CurrentFloor))
@@ -317,10 +849,10 @@ func (e *BitmaskElevator) HasPendingRequests() bool {
 	return (e.cabUpStops | e.cabDownStops | e.hallUpStops | e.hallDownStops) != 0
 }
 
-// PendingCount — O(1): popcount via bits.OnesCount64.
+// PendingCount — O(1): popcount via bits.OnesCount64. A cab and a hall call
+// at the same floor in the same direction are one stop.
 func (e *BitmaskElevator) PendingCount() int {
-	return bits.OnesCount64(e.cabUpStops) + bits.OnesCount64(e.cabDownStops) +
-		bits.OnesCount64(e.hallUpStops) + bits.OnesCount64(e.hallDownStops)
+	return bits.OnesCount64(e.cabUpStops|e.hallUpStops) + bits.OnesCount64(e.cabDownStops|e.hallDownStops)
 }
 
 // StopsCabSnapshot returns the cab stop floors in each direction.
@@ -352,8 +884,3 @@ func (e *BitmaskElevator) StopsHallSnapshot() (up []int, down []int) {
 	}
 	return
 }
-
-// WeightSensor check is overweight
-func (e *BitmaskElevator) WeightSensor() bool {
-	return e.currentWeight >= e.maxWeight
-}
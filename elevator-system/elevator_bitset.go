@@ -1,10 +1,6 @@
 package main
 
-import (
-	"fmt"
-
-	"github.com/bits-and-blooms/bitset"
-)
+import "github.com/bits-and-blooms/bitset"
 
 // BitsetElevator is an alternative Elevator implementation that uses
 // github.com/bits-and-blooms/bitset for tracking stops.
@@ -33,8 +29,21 @@ type BitsetElevator struct {
 
 	doorTimer int
 
-	currentWeight int
-	maxWeight     int
+	// maintenance: finish current stops, accept no new requests.
+	maintenance bool
+
+	// parking: moving to parkFloor with no request, dropped on the first real one.
+	parking   bool
+	parkFloor int
+
+	cabin
+	vipQueue
+	motion
+	zone
+	fireService
+	emergency
+	faultState
+	door
 }
 
 // NewBitsetElevator creates an elevator using bitset stops.
@@ -51,10 +60,25 @@ func NewBitsetElevator(id, minFloor, maxFloor int) *BitsetElevator {
 		cabDownStops:  bitset.New(n),
 		hallUpStops:   bitset.New(n),
 		hallDownStops: bitset.New(n),
-		maxWeight:     100,
+		cabin:         newCabin(),
+		door:          newDoor(),
+		motion:        newMotion(),
+		zone:          newZone(minFloor, maxFloor),
 	}
 }
 
+// CarID returns the elevator's identifier.
+func (e *BitsetElevator) CarID() int { return e.ID }
+
+// Floor returns the floor the elevator is currently at.
+func (e *BitsetElevator) Floor() int { return e.CurrentFloor }
+
+// CurrentState returns the elevator's state.
+func (e *BitsetElevator) CurrentState() ElevatorState { return e.State }
+
+// CurrentDirection returns the elevator's travel direction.
+func (e *BitsetElevator) CurrentDirection() Direction { return e.Direction }
+
 // idx converts a floor number to the bit position.
 func (e *BitsetElevator) idx(floor int) uint {
 	return uint(floor - e.MinFloor)
@@ -62,13 +86,35 @@ func (e *BitsetElevator) idx(floor int) uint {
 
 // --- Core elevator logic (same LOOK algorithm) ---
 
-func (e *BitsetElevator) AddRequest(r Request) {
-	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor {
-		return
+func (e *BitsetElevator) AddRequest(r Request) error {
+	if e.mode != EmergencyNone {
+		return ErrOutOfService
+	}
+	if e.refuses(r) {
+		return ErrFireService
 	}
-	if r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State == StateDoorOpen) {
+	if e.maintenance {
+		return ErrInMaintenance
+	}
+	return e.addRequest(r)
+}
+
+func (e *BitsetElevator) addRequest(r Request) error {
+	if !e.Serves(r.Floor) {
+		return ErrFloorNotServed
+	}
+	if e.parking {
+		e.stopParking()
+	}
+	atFloor := r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State.loading())
+	vip := r.Priority > PriorityNormal && (atFloor || r.Floor != e.CurrentFloor)
+	if vip {
+		e.pushVIP(r.Floor)
+	}
+	if atFloor {
+		e.opening = e.opening || e.State == StateIdle
 		e.openDoor(DirIdle)
-		return
+		return nil
 	}
 
 	i := e.idx(r.Floor)
@@ -80,10 +126,14 @@ func (e *BitsetElevator) AddRequest(r Request) {
 			e.hallDownStops.Set(i)
 		}
 	case CabCall:
-		if r.Floor > e.CurrentFloor {
+		switch r.cabDirection(e.CurrentFloor) {
+		case DirUp:
 			e.cabUpStops.Set(i)
-		} else if r.Floor < e.CurrentFloor {
+		case DirDown:
 			e.cabDownStops.Set(i)
+		default:
+			// The car is leaving this floor: a cab call for it is not latched.
+			return nil
 		}
 	}
 
@@ -96,55 +146,539 @@ func (e *BitsetElevator) AddRequest(r Request) {
 			e.State = StateMovingDown
 		}
 	}
+	if vip {
+		e.reconsiderDirection()
+	}
+	return nil
+}
+
+// AddPassenger assigns a waiting passenger to this car and places its hall call.
+func (e *BitsetElevator) AddPassenger(p *Passenger) {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(p.Origin) {
+		return
+	}
+	e.await(p)
+	e.AddRequest(p.HallRequest())
+}
+
+// SetMaintenance takes the elevator out of service or puts it back.
+// In maintenance it finishes the stops it already has but refuses new requests.
+func (e *BitsetElevator) SetMaintenance(on bool) { e.maintenance = on }
+
+// InMaintenance reports whether the elevator is in maintenance.
+func (e *BitsetElevator) InMaintenance() bool { return e.maintenance }
+
+// Park sends an idle car to floor to wait for calls there. The move does not
+// open the door and is abandoned as soon as the car gets a real request.
+// Returns false if the car is busy, in maintenance, fire service or an
+// emergency stop, or does not serve floor.
+func (e *BitsetElevator) Park(floor int) bool {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(floor) ||
+		e.HasPendingRequests() || (e.State != StateIdle && !e.parking) {
+		return false
+	}
+	e.stopParking()
+	switch {
+	case floor > e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	case floor < e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+	return true
+}
+
+// Parking reports whether the car is on its way to a parking floor.
+func (e *BitsetElevator) Parking() bool { return e.parking }
+
+// HoldDoor is the door-open-hold button; see Elevator.HoldDoor.
+func (e *BitsetElevator) HoldDoor() bool {
+	switch {
+	case e.State == StateDoorOpen && e.doorConfig.MaxHoldSteps > 0:
+		e.State, e.doorTimer = StateDoorHold, e.doorConfig.MaxHoldSteps
+	case e.State == StateDoorHold:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	default:
+		return false
+	}
+	return true
+}
+
+// CloseDoor is the door-close button; see Elevator.CloseDoor.
+func (e *BitsetElevator) CloseDoor() bool {
+	if e.State != StateDoorOpen && e.State != StateDoorHold {
+		return false
+	}
+	e.State, e.doorTimer = StateDoorOpen, 1
+	return true
+}
+
+// FireRecall puts the car under Phase I fire recall; see Elevator.FireRecall.
+func (e *BitsetElevator) FireRecall(floor int) error {
+	if !e.Serves(floor) {
+		return ErrFloorNotServed
+	}
+	e.recall, e.recallFloor = true, floor
+	if e.firefighter {
+		return nil
+	}
+	e.clearStops()
+	e.runToRecall()
+	return nil
+}
+
+// EndFireRecall resets Phase I; see Elevator.EndFireRecall.
+func (e *BitsetElevator) EndFireRecall() {
+	if !e.recall {
+		return
+	}
+	e.recall = false
+	if !e.firefighter {
+		e.resumeService()
+	}
+}
+
+// SetFirefighterService turns the car's Phase II key switch; see
+// Elevator.SetFirefighterService.
+func (e *BitsetElevator) SetFirefighterService(on bool) error {
+	if on == e.firefighter {
+		return nil
+	}
+	if on {
+		if !e.recalling() || e.State != StateFireService {
+			return ErrNotRecalled
+		}
+		e.firefighter = true
+		return nil
+	}
+	e.firefighter = false
+	e.clearStops()
+	if e.recall {
+		e.runToRecall()
+	} else {
+		e.resumeService()
+	}
+	return nil
+}
+
+// clearStops cancels every stop, VIP target and parking move, and turns
+// away the passengers waiting for the car.
+func (e *BitsetElevator) clearStops() {
+	e.cabUpStops.ClearAll()
+	e.cabDownStops.ClearAll()
+	e.hallUpStops.ClearAll()
+	e.hallDownStops.ClearAll()
+	e.vipQueue = vipQueue{}
+	e.parking = false
+	e.turnAway()
+}
+
+// runToRecall heads the car for its recall floor, or parks it there.
+func (e *BitsetElevator) runToRecall() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch {
+	case e.CurrentFloor == e.recallFloor:
+		e.parkRecalled()
+	case e.State.doorOpen():
+		e.State, e.doorTimer = StateDoorOpen, 1
+	case e.recallFloor > e.CurrentFloor:
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	default:
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+}
+
+func (e *BitsetElevator) parkRecalled() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateFireService
+}
+
+func (e *BitsetElevator) resumeService() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch e.State {
+	case StateFireService:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	case StateMovingUp, StateMovingDown:
+		e.pickDirection()
+	}
+}
+
+// SeismicStop stops the car for an earthquake; see Elevator.SeismicStop.
+func (e *BitsetElevator) SeismicStop() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	e.mode = EmergencySeismic
+	e.clearStops()
+	if e.State != StateMovingUp && e.State != StateMovingDown {
+		e.emergencyStop()
+	}
+}
+
+// PowerFailure stops the car where it is; see Elevator.PowerFailure.
+func (e *BitsetElevator) PowerFailure() {
+	if e.mode == EmergencyPowerFailure {
+		return
+	}
+	e.mode = EmergencyPowerFailure
+	if e.State == StateEmergencyStop {
+		return
+	}
+	e.clearStops()
+	switch {
+	case e.State.doorOpen():
+		e.emergencyStop()
+		return
+	case e.State == StateMovingDown:
+		e.lowerTo = e.CurrentFloor - 1
+	default:
+		e.lowerTo = e.CurrentFloor
+	}
+	e.State = StateStranded
+}
+
+// LowerToFloor runs a stranded car on emergency power to the nearest floor;
+// see Elevator.LowerToFloor.
+func (e *BitsetElevator) LowerToFloor() bool {
+	if e.State != StateStranded {
+		return false
+	}
+	e.State = StateLowering
+	if e.lowerTo < e.CurrentFloor {
+		e.Direction = DirDown
+	}
+	return true
+}
+
+// ResetEmergency puts the car back into service; see Elevator.ResetEmergency.
+func (e *BitsetElevator) ResetEmergency() {
+	if e.mode == EmergencyNone {
+		return
+	}
+	e.mode = EmergencyNone
+	if e.State == StateEmergencyStop {
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return
+	}
+	e.State = StateIdle
+	if !e.recalling() {
+		for _, p := range e.occupants {
+			e.addRequest(p.CabRequest())
+		}
+	}
+	e.pickDirection()
+}
+
+func (e *BitsetElevator) emergencyStop() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateEmergencyStop
+}
+
+func (e *BitsetElevator) stopParking() {
+	e.parking = false
+	e.Direction = DirIdle
+	e.State = StateIdle
+}
+
+// CancelRequest removes the stop r set; see Elevator.CancelRequest.
+func (e *BitsetElevator) CancelRequest(r Request) bool {
+	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor || !e.cancellable(r) {
+		return false
+	}
+	i := e.idx(r.Floor)
+	var had bool
+	switch {
+	case r.Type == CabCall:
+		had = e.cabUpStops.Test(i) || e.cabDownStops.Test(i)
+		e.cabUpStops.Clear(i)
+		e.cabDownStops.Clear(i)
+	case r.Direction == DirUp:
+		had = e.hallUpStops.Test(i)
+		e.hallUpStops.Clear(i)
+	default:
+		had = e.hallDownStops.Test(i)
+		e.hallDownStops.Clear(i)
+	}
+	if !had {
+		return false
+	}
+	if !e.hasStopAt(i) {
+		e.dropVIP(r.Floor)
+	}
+	e.reconsiderDirection()
+	return true
+}
+
+// PressCabButton is a floor button in the cab; see Elevator.PressCabButton.
+func (e *BitsetElevator) PressCabButton(floor int) error {
+	if e.CancelRequest(Request{Floor: floor, Type: CabCall}) {
+		return nil
+	}
+	return e.AddRequest(Request{Floor: floor, Type: CabCall})
+}
+
+// ReleaseHallCall drops the hall stop at floor in direction dir and returns
+// the passengers waiting for it, so another car can take over the call.
+func (e *BitsetElevator) ReleaseHallCall(floor int, dir Direction) []*Passenger {
+	if floor < e.MinFloor || floor > e.MaxFloor {
+		return nil
+	}
+	if dir == DirUp {
+		e.hallUpStops.Clear(e.idx(floor))
+	} else {
+		e.hallDownStops.Clear(e.idx(floor))
+	}
+	if !e.hasStopAt(e.idx(floor)) {
+		e.dropVIP(floor)
+	}
+	e.reconsiderDirection()
+	return e.release(floor, dir)
+}
+
+// reconsiderDirection re-runs the direction choice of a moving car after its
+// stops changed.
+func (e *BitsetElevator) reconsiderDirection() {
+	if e.parking || (e.State != StateMovingUp && e.State != StateMovingDown) {
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f == e.CurrentFloor {
+		// The VIP stop being run to went away; the next one is here.
+		e.opening = true
+		e.openDoor(DirIdle)
+		return
+	}
+	e.pickDirection()
+	if e.State == StateIdle && e.HasPendingRequests() {
+		e.opening = true
+		e.openDoor(DirIdle)
+	}
 }
 
 func (e *BitsetElevator) Step() string {
+	return FormatStep(e.StepEvents())
+}
+
+// StepEvents advances the elevator by one time unit and returns what happened.
+func (e *BitsetElevator) StepEvents() []StepEvent {
+	var opened []StepEvent
+	if e.opening {
+		e.opening = false
+		opened = []StepEvent{e.event(StepDoorOpened)}
+	}
+	return append(opened, e.step()...)
+}
+
+// step runs the car's state machine for one time unit.
+func (e *BitsetElevator) step() []StepEvent {
+	if e.stalls(e.State, e.doorTimer) {
+		ev := e.event(StepFault)
+		ev.Fault = e.fault
+		return []StepEvent{ev}
+	}
+	dir := e.Direction
+	var evs []StepEvent
 	switch e.State {
 	case StateDoorOpen:
-		return e.stepDoorOpen()
+		evs = e.stepDoorOpen()
+	case StateDoorHold:
+		evs = e.stepDoorHold()
+	case StateNudging:
+		evs = e.stepNudging()
+	case StateOverloaded:
+		evs = e.stepOverloaded()
+	case StateFireService:
+		evs = e.stepFireService()
+	case StateStranded:
+		evs = []StepEvent{e.event(StepStranded)}
+	case StateLowering:
+		evs = e.stepLowering()
+	case StateEmergencyStop:
+		evs = []StepEvent{e.event(StepOutOfService)}
 	case StateMovingUp:
-		return e.stepMove(DirUp)
+		evs = e.stepMove(DirUp)
 	case StateMovingDown:
-		return e.stepMove(DirDown)
+		evs = e.stepMove(DirDown)
 	default:
-		return e.stepIdle()
+		evs = e.stepIdle()
+	}
+	if e.Direction != dir {
+		evs = append(evs, e.event(StepDirectionChanged))
 	}
+	return evs
+}
+
+// event returns a StepEvent of the given kind at the car's current position.
+func (e *BitsetElevator) event(kind StepKind) StepEvent {
+	return StepEvent{Kind: kind, CarID: e.ID, Floor: e.CurrentFloor, Direction: e.Direction}
 }
 
-func (e *BitsetElevator) stepDoorOpen() string {
+func (e *BitsetElevator) stepDoorOpen() []StepEvent {
 	e.doorTimer--
 	if e.doorTimer > 0 {
-		return fmt.Sprintf("Elevator %d: door open at floor %d (closing in %d)",
-			e.ID, e.CurrentFloor, e.doorTimer)
+		ev := e.event(StepDoorHeld)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
 	}
+	return e.closeDoor()
+}
+
+// stepDoorHold keeps the door open for the hold button; see Elevator.stepDoorHold.
+func (e *BitsetElevator) stepDoorHold() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		ev := e.event(StepHoldOpen)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
+	}
+	return e.closeDoor()
+}
+
+// closeDoor closes the door unless the obstruction sensor reopens it; see
+// Elevator.closeDoor.
+func (e *BitsetElevator) closeDoor() []StepEvent {
+	if e.obstructed {
+		if e.reopen() {
+			e.State, e.doorTimer = StateNudging, nudgeSteps
+			return []StepEvent{e.event(StepNudging)}
+		}
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return []StepEvent{e.event(StepDoorReopened)}
+	}
+	return e.shutDoor()
+}
+
+// stepNudging closes the door slowly, ignoring the obstruction sensor.
+func (e *BitsetElevator) stepNudging() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		return []StepEvent{e.event(StepNudging)}
+	}
+	return e.shutDoor()
+}
+
+// shutDoor closes the door and decides the next action.
+func (e *BitsetElevator) shutDoor() []StepEvent {
+	e.reopens = 0
 	e.State = StateIdle
+	if e.antiNuisance && len(e.occupants) == 0 && e.terminal(e.CurrentFloor) {
+		cancelCabCalls(e)
+	}
 	e.pickDirection()
-	return fmt.Sprintf("Elevator %d: door closed at floor %d, direction=%s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+// stepOverloaded sounds the overload alarm with the door held open. Each step
+// the last passenger to board here steps back off and waits for the car's
+// next pass; once the car is within capacity the door re-times and closes
+// as usual.
+func (e *BitsetElevator) stepOverloaded() []StepEvent {
+	p := e.stepOff(e.CurrentFloor)
+	if p != nil {
+		e.addRequest(p.HallRequest())
+	}
+	if p == nil || !e.overloaded() {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
+	return []StepEvent{e.event(StepOverloadAlarm)}
+}
+
+// stepLowering runs a car on emergency power down to its lowering floor.
+func (e *BitsetElevator) stepLowering() []StepEvent {
+	var evs []StepEvent
+	if e.CurrentFloor > e.lowerTo {
+		e.CurrentFloor--
+		evs = append(evs, e.event(StepMoved))
+		if e.CurrentFloor > e.lowerTo {
+			return evs
+		}
+	}
+	e.emergencyStop()
+	return append(evs, e.event(StepEmergencyStop))
 }
 
-func (e *BitsetElevator) stepMove(dir Direction) string {
+// stepFireService holds the door open in fire service until a Phase II cab call.
+func (e *BitsetElevator) stepFireService() []StepEvent {
+	if !e.firefighter || !e.HasPendingRequests() {
+		return []StepEvent{e.event(StepFireService)}
+	}
+	e.State = StateIdle
+	e.pickDirection()
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+func (e *BitsetElevator) stepMove(dir Direction) []StepEvent {
 	if dir == DirUp {
 		e.CurrentFloor++
 	} else {
 		e.CurrentFloor--
 	}
 
-	msg := fmt.Sprintf("Elevator %d: moved to floor %d", e.ID, e.CurrentFloor)
+	evs := []StepEvent{e.event(StepMoved)}
+	if e.mode == EmergencySeismic {
+		// Seismic stop at the first floor reached.
+		e.emergencyStop()
+		return append(evs, e.event(StepEmergencyStop))
+	}
+	if e.recalling() {
+		// Fire recall: run non-stop to the recall floor.
+		if e.CurrentFloor == e.recallFloor {
+			e.parkRecalled()
+			evs = append(evs, e.event(StepRecalled))
+		}
+		return evs
+	}
+	if e.parking {
+		if e.CurrentFloor == e.parkFloor {
+			e.stopParking()
+			evs = append(evs, e.event(StepParked))
+		}
+		return evs
+	}
+
+	if f, ok := e.vipTarget(); ok {
+		if e.CurrentFloor == f {
+			stop := e.event(StepStopped)
+			stop.VIP = true
+			e.openDoor(DirIdle)
+			evs = append(evs, stop, e.event(StepDoorOpened))
+		}
+		return evs
+	}
+
 	if e.shouldStop(dir) {
+		evs = append(evs, e.event(StepStopped))
 		e.openDoor(dir)
-		msg += " [STOP — door opening]"
+		evs = append(evs, e.event(StepDoorOpened))
+	} else if e.WeightSensor() && e.hallStopAt(dir) {
+		evs = append(evs, e.event(StepOverloaded))
 	}
-	return msg
+	return evs
 }
 
-func (e *BitsetElevator) stepIdle() string {
+func (e *BitsetElevator) stepIdle() []StepEvent {
 	e.pickDirection()
-	if e.State == StateIdle {
-		return fmt.Sprintf("Elevator %d: idle at floor %d", e.ID, e.CurrentFloor)
+	return []StepEvent{e.event(StepIdle)}
+}
+
+// hallStopAt reports whether the current floor has a hall stop in direction dir.
+func (e *BitsetElevator) hallStopAt(dir Direction) bool {
+	i := e.idx(e.CurrentFloor)
+	if dir == DirUp {
+		return e.hallUpStops.Test(i)
 	}
-	return fmt.Sprintf("Elevator %d: idle at floor %d, starting %s",
-		e.ID, e.CurrentFloor, e.Direction)
+	return e.hallDownStops.Test(i)
 }
 
 func (e *BitsetElevator) shouldStop(dir Direction) bool {
@@ -178,60 +712,52 @@ func (e *BitsetElevator) shouldStop(dir Direction) bool {
 }
 
 func (e *BitsetElevator) openDoor(dir Direction) {
-	e.State = StateDoorOpen
-	e.doorTimer = doorOpenSteps
+	if e.State != StateDoorHold {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
 	i := e.idx(e.CurrentFloor)
 
-	if dir == DirUp || dir == DirIdle {
-		if e.cabUpStops.Test(i) {
-			e.cabUpStops.Clear(i)
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallUpStops.Test(i) {
-			e.hallUpStops.Clear(i)
-			e.currentWeight += passengerWeight
-		}
+	up := dir == DirUp || dir == DirIdle || (dir == DirDown && !e.hasStopsBelow())
+	down := dir == DirDown || dir == DirIdle || (dir == DirUp && !e.hasStopsAbove())
+	if up {
+		e.cabUpStops.Clear(i)
+		e.hallUpStops.Clear(i)
 	}
-	if dir == DirDown || dir == DirIdle {
-		if e.cabDownStops.Test(i) {
-			e.cabDownStops.Clear(i)
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallDownStops.Test(i) {
-			e.hallDownStops.Clear(i)
-			e.currentWeight += passengerWeight
-		}
+	if down {
+		e.cabDownStops.Clear(i)
+		e.hallDownStops.Clear(i)
 	}
 
-	// Turnaround: also clear opposite direction stop.
-	if dir == DirUp && !e.hasStopsAbove() {
-		if e.cabDownStops.Test(i) {
-			e.cabDownStops.Clear(i)
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallDownStops.Test(i) {
-			e.hallDownStops.Clear(i)
-			e.currentWeight += passengerWeight
-		}
-	}
-	if dir == DirDown && !e.hasStopsBelow() {
-		if e.cabUpStops.Test(i) {
-			e.cabUpStops.Clear(i)
-			e.currentWeight -= passengerWeight
-		}
-		if e.hallUpStops.Test(i) {
-			e.hallUpStops.Clear(i)
-			e.currentWeight += passengerWeight
-		}
+	e.alight(e.CurrentFloor)
+	for _, p := range e.board(e.CurrentFloor, up, down) {
+		e.addRequest(p.CabRequest())
 	}
+	e.vipServed(e.CurrentFloor)
 
-	// Clamp weight to non-negative.
-	if e.currentWeight < 0 {
-		e.currentWeight = 0
+	if e.firefighter {
+		// Phase II: the door stays open until the next cab call.
+		e.State = StateFireService
+	} else if e.overloaded() {
+		e.State = StateOverloaded
 	}
 }
 
 func (e *BitsetElevator) pickDirection() {
+	if e.recalling() {
+		e.runToRecall()
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f != e.CurrentFloor {
+		if f > e.CurrentFloor {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+		} else {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+		}
+		return
+	}
 	switch e.Direction {
 	case DirUp:
 		if e.hasStopsAbove() {
@@ -269,6 +795,11 @@ func (e *BitsetElevator) pickDirection() {
 	e.State = StateIdle
 }
 
+// hasStopAt reports whether any stop set holds bit i.
+func (e *BitsetElevator) hasStopAt(i uint) bool {
+	return e.cabUpStops.Test(i) || e.cabDownStops.Test(i) || e.hallUpStops.Test(i) || e.hallDownStops.Test(i)
+}
+
 // hasStopsAbove uses NextSet to find the first set bit above the current floor.
 func (e *BitsetElevator) hasStopsAbove() bool {
 	start := e.idx(e.CurrentFloor) + 1
@@ -315,10 +846,11 @@ func (e *BitsetElevator) HasPendingRequests() bool {
 		e.hallUpStops.Any() || e.hallDownStops.Any()
 }
 
-// PendingCount returns the total number of pending stops.
+// PendingCount returns the total number of pending stops. A cab and a hall
+// call at the same floor in the same direction are one stop.
 func (e *BitsetElevator) PendingCount() int {
-	return int(e.cabUpStops.Count() + e.cabDownStops.Count() +
-		e.hallUpStops.Count() + e.hallDownStops.Count())
+	return int(e.cabUpStops.UnionCardinality(e.hallUpStops) +
+		e.cabDownStops.UnionCardinality(e.hallDownStops))
 }
 
 // StopsCabSnapshot returns the cab stop floors in each direction.
@@ -344,8 +876,3 @@ func (e *BitsetElevator) StopsHallSnapshot() (up []int, down []int) {
 	}
 	return
 }
-
-// WeightSensor check is overweight
-func (e *BitsetElevator) WeightSensor() bool {
-	return e.currentWeight >= e.maxWeight
-}
@@ -0,0 +1,896 @@
+package main
+
+import "math/bits"
+
+// MultiwordElevator is the bitmask implementation without the 64-floor limit:
+// each stop set is a []uint64, bit i of the whole slice = floor (i + MinFloor).
+//
+// It keeps the hand-rolled tricks of BitmaskElevator and applies them word
+// by word, with no external dependency:
+//   - hasStopsAbove / hasStopsBelow mask the current floor's word with
+//     aboveMask / belowMask, then only test whole words for != 0
+//   - HasPendingRequests ORs the four sets word by word
+//   - PendingCount is bits.OnesCount64 per word
+//
+// Those are O(n/64) instead of O(1); for up to 64 floors there is one word
+// and the cost matches BitmaskElevator.
+type MultiwordElevator struct {
+	ID           int
+	CurrentFloor int
+	State        ElevatorState
+	Direction    Direction
+	MinFloor     int
+	MaxFloor     int
+
+	cabUpStops    words // bitmask: cab calls going up
+	cabDownStops  words // bitmask: cab calls going down
+	hallUpStops   words // bitmask: hall calls going up
+	hallDownStops words // bitmask: hall calls going down
+
+	doorTimer int
+
+	// maintenance: finish current stops, accept no new requests.
+	maintenance bool
+
+	// parking: moving to parkFloor with no request, dropped on the first real one.
+	parking   bool
+	parkFloor int
+
+	cabin
+	vipQueue
+	motion
+	zone
+	fireService
+	emergency
+	faultState
+	door
+}
+
+// NewMultiwordElevator creates an elevator using multi-word bitmask stops,
+// for any number of floors.
+func NewMultiwordElevator(id, minFloor, maxFloor int) *MultiwordElevator {
+	n := (maxFloor - minFloor + 64) / 64 // ceil(floors / 64)
+	return &MultiwordElevator{
+		ID:            id,
+		CurrentFloor:  minFloor,
+		State:         StateIdle,
+		Direction:     DirIdle,
+		MinFloor:      minFloor,
+		MaxFloor:      maxFloor,
+		cabUpStops:    make(words, n),
+		cabDownStops:  make(words, n),
+		hallUpStops:   make(words, n),
+		hallDownStops: make(words, n),
+		cabin:         newCabin(),
+		door:          newDoor(),
+		motion:        newMotion(),
+		zone:          newZone(minFloor, maxFloor),
+	}
+}
+
+// CarID returns the elevator's identifier.
+func (e *MultiwordElevator) CarID() int { return e.ID }
+
+// Floor returns the floor the elevator is currently at.
+func (e *MultiwordElevator) Floor() int { return e.CurrentFloor }
+
+// CurrentState returns the elevator's state.
+func (e *MultiwordElevator) CurrentState() ElevatorState { return e.State }
+
+// CurrentDirection returns the elevator's travel direction.
+func (e *MultiwordElevator) CurrentDirection() Direction { return e.Direction }
+
+// --- Bit manipulation helpers ---
+
+// words is a bitmask spread over as many uint64 words as needed:
+// bit i lives in word i/64 at position i%64.
+type words []uint64
+
+// idx converts a floor number to the bit position.
+func (e *MultiwordElevator) idx(floor int) uint {
+	return uint(floor - e.MinFloor)
+}
+
+// set sets the given bit.
+func (w words) set(bit uint) { w[bit/64] |= 1 << (bit % 64) }
+
+// clear clears the given bit.
+func (w words) clear(bit uint) { w[bit/64] &^= 1 << (bit % 64) }
+
+// has checks if the given bit is set.
+func (w words) has(bit uint) bool { return w[bit/64]&(1<<(bit%64)) != 0 }
+
+// floors lists the floors whose bits are set, lowest first.
+func (w words) floors(minFloor int) []int {
+	var fs []int
+	for i, word := range w {
+		for b := word; b != 0; b &= b - 1 {
+			fs = append(fs, i*64+bits.TrailingZeros64(b)+minFloor)
+		}
+	}
+	return fs
+}
+
+// union returns word i of all four stop sets ORed together.
+func (e *MultiwordElevator) union(i int) uint64 {
+	return e.cabUpStops[i] | e.cabDownStops[i] | e.hallUpStops[i] | e.hallDownStops[i]
+}
+
+// --- Core elevator logic (same LOOK algorithm, different data structure) ---
+
+func (e *MultiwordElevator) AddRequest(r Request) error {
+	if e.mode != EmergencyNone {
+		return ErrOutOfService
+	}
+	if e.refuses(r) {
+		return ErrFireService
+	}
+	if e.maintenance {
+		return ErrInMaintenance
+	}
+	return e.addRequest(r)
+}
+
+func (e *MultiwordElevator) addRequest(r Request) error {
+	if !e.Serves(r.Floor) {
+		return ErrFloorNotServed
+	}
+	if e.parking {
+		e.stopParking()
+	}
+	atFloor := r.Floor == e.CurrentFloor && (e.State == StateIdle || e.State.loading())
+	vip := r.Priority > PriorityNormal && (atFloor || r.Floor != e.CurrentFloor)
+	if vip {
+		e.pushVIP(r.Floor)
+	}
+	if atFloor {
+		e.opening = e.opening || e.State == StateIdle
+		e.openDoor(DirIdle)
+		return nil
+	}
+
+	bit := e.idx(r.Floor)
+	switch r.Type {
+	case HallCall:
+		if r.Direction == DirUp {
+			e.hallUpStops.set(bit)
+		} else {
+			e.hallDownStops.set(bit)
+		}
+	case CabCall:
+		switch r.cabDirection(e.CurrentFloor) {
+		case DirUp:
+			e.cabUpStops.set(bit)
+		case DirDown:
+			e.cabDownStops.set(bit)
+		default:
+			// The car is leaving this floor: a cab call for it is not latched.
+			return nil
+		}
+	}
+
+	if e.State == StateIdle {
+		if r.Floor > e.CurrentFloor {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+		} else if r.Floor < e.CurrentFloor {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+		}
+	}
+	if vip {
+		e.reconsiderDirection()
+	}
+	return nil
+}
+
+// AddPassenger assigns a waiting passenger to this car and places its hall call.
+func (e *MultiwordElevator) AddPassenger(p *Passenger) {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(p.Origin) {
+		return
+	}
+	e.await(p)
+	e.AddRequest(p.HallRequest())
+}
+
+// SetMaintenance takes the elevator out of service or puts it back.
+// In maintenance it finishes the stops it already has but refuses new requests.
+func (e *MultiwordElevator) SetMaintenance(on bool) { e.maintenance = on }
+
+// InMaintenance reports whether the elevator is in maintenance.
+func (e *MultiwordElevator) InMaintenance() bool { return e.maintenance }
+
+// Park sends an idle car to floor to wait for calls there. The move does not
+// open the door and is abandoned as soon as the car gets a real request.
+// Returns false if the car is busy, in maintenance, fire service or an
+// emergency stop, or does not serve floor.
+func (e *MultiwordElevator) Park(floor int) bool {
+	if e.maintenance || e.FirePhase() != FireOff || e.mode != EmergencyNone || !e.Serves(floor) ||
+		e.HasPendingRequests() || (e.State != StateIdle && !e.parking) {
+		return false
+	}
+	e.stopParking()
+	switch {
+	case floor > e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	case floor < e.CurrentFloor:
+		e.parking, e.parkFloor = true, floor
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+	return true
+}
+
+// Parking reports whether the car is on its way to a parking floor.
+func (e *MultiwordElevator) Parking() bool { return e.parking }
+
+// HoldDoor is the door-open-hold button; see Elevator.HoldDoor.
+func (e *MultiwordElevator) HoldDoor() bool {
+	switch {
+	case e.State == StateDoorOpen && e.doorConfig.MaxHoldSteps > 0:
+		e.State, e.doorTimer = StateDoorHold, e.doorConfig.MaxHoldSteps
+	case e.State == StateDoorHold:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	default:
+		return false
+	}
+	return true
+}
+
+// CloseDoor is the door-close button; see Elevator.CloseDoor.
+func (e *MultiwordElevator) CloseDoor() bool {
+	if e.State != StateDoorOpen && e.State != StateDoorHold {
+		return false
+	}
+	e.State, e.doorTimer = StateDoorOpen, 1
+	return true
+}
+
+// FireRecall puts the car under Phase I fire recall; see Elevator.FireRecall.
+func (e *MultiwordElevator) FireRecall(floor int) error {
+	if !e.Serves(floor) {
+		return ErrFloorNotServed
+	}
+	e.recall, e.recallFloor = true, floor
+	if e.firefighter {
+		return nil
+	}
+	e.clearStops()
+	e.runToRecall()
+	return nil
+}
+
+// EndFireRecall resets Phase I; see Elevator.EndFireRecall.
+func (e *MultiwordElevator) EndFireRecall() {
+	if !e.recall {
+		return
+	}
+	e.recall = false
+	if !e.firefighter {
+		e.resumeService()
+	}
+}
+
+// SetFirefighterService turns the car's Phase II key switch; see
+// Elevator.SetFirefighterService.
+func (e *MultiwordElevator) SetFirefighterService(on bool) error {
+	if on == e.firefighter {
+		return nil
+	}
+	if on {
+		if !e.recalling() || e.State != StateFireService {
+			return ErrNotRecalled
+		}
+		e.firefighter = true
+		return nil
+	}
+	e.firefighter = false
+	e.clearStops()
+	if e.recall {
+		e.runToRecall()
+	} else {
+		e.resumeService()
+	}
+	return nil
+}
+
+// clearStops cancels every stop, VIP target and parking move, and turns
+// away the passengers waiting for the car.
+func (e *MultiwordElevator) clearStops() {
+	for i := range e.cabUpStops {
+		e.cabUpStops[i], e.cabDownStops[i], e.hallUpStops[i], e.hallDownStops[i] = 0, 0, 0, 0
+	}
+	e.vipQueue = vipQueue{}
+	e.parking = false
+	e.turnAway()
+}
+
+// runToRecall heads the car for its recall floor, or parks it there.
+func (e *MultiwordElevator) runToRecall() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch {
+	case e.CurrentFloor == e.recallFloor:
+		e.parkRecalled()
+	case e.State.doorOpen():
+		e.State, e.doorTimer = StateDoorOpen, 1
+	case e.recallFloor > e.CurrentFloor:
+		e.Direction = DirUp
+		e.State = StateMovingUp
+	default:
+		e.Direction = DirDown
+		e.State = StateMovingDown
+	}
+}
+
+func (e *MultiwordElevator) parkRecalled() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateFireService
+}
+
+func (e *MultiwordElevator) resumeService() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	switch e.State {
+	case StateFireService:
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+	case StateMovingUp, StateMovingDown:
+		e.pickDirection()
+	}
+}
+
+// SeismicStop stops the car for an earthquake; see Elevator.SeismicStop.
+func (e *MultiwordElevator) SeismicStop() {
+	if e.mode != EmergencyNone {
+		return
+	}
+	e.mode = EmergencySeismic
+	e.clearStops()
+	if e.State != StateMovingUp && e.State != StateMovingDown {
+		e.emergencyStop()
+	}
+}
+
+// PowerFailure stops the car where it is; see Elevator.PowerFailure.
+func (e *MultiwordElevator) PowerFailure() {
+	if e.mode == EmergencyPowerFailure {
+		return
+	}
+	e.mode = EmergencyPowerFailure
+	if e.State == StateEmergencyStop {
+		return
+	}
+	e.clearStops()
+	switch {
+	case e.State.doorOpen():
+		e.emergencyStop()
+		return
+	case e.State == StateMovingDown:
+		e.lowerTo = e.CurrentFloor - 1
+	default:
+		e.lowerTo = e.CurrentFloor
+	}
+	e.State = StateStranded
+}
+
+// LowerToFloor runs a stranded car on emergency power to the nearest floor;
+// see Elevator.LowerToFloor.
+func (e *MultiwordElevator) LowerToFloor() bool {
+	if e.State != StateStranded {
+		return false
+	}
+	e.State = StateLowering
+	if e.lowerTo < e.CurrentFloor {
+		e.Direction = DirDown
+	}
+	return true
+}
+
+// ResetEmergency puts the car back into service; see Elevator.ResetEmergency.
+func (e *MultiwordElevator) ResetEmergency() {
+	if e.mode == EmergencyNone {
+		return
+	}
+	e.mode = EmergencyNone
+	if e.State == StateEmergencyStop {
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return
+	}
+	e.State = StateIdle
+	if !e.recalling() {
+		for _, p := range e.occupants {
+			e.addRequest(p.CabRequest())
+		}
+	}
+	e.pickDirection()
+}
+
+func (e *MultiwordElevator) emergencyStop() {
+	e.evacuate()
+	e.Direction = DirIdle
+	e.State = StateEmergencyStop
+}
+
+func (e *MultiwordElevator) stopParking() {
+	e.parking = false
+	e.Direction = DirIdle
+	e.State = StateIdle
+}
+
+// CancelRequest removes the stop r set; see Elevator.CancelRequest.
+func (e *MultiwordElevator) CancelRequest(r Request) bool {
+	if r.Floor < e.MinFloor || r.Floor > e.MaxFloor || !e.cancellable(r) {
+		return false
+	}
+	bit := e.idx(r.Floor)
+	var had bool
+	switch {
+	case r.Type == CabCall:
+		had = e.cabUpStops.has(bit) || e.cabDownStops.has(bit)
+		e.cabUpStops.clear(bit)
+		e.cabDownStops.clear(bit)
+	case r.Direction == DirUp:
+		had = e.hallUpStops.has(bit)
+		e.hallUpStops.clear(bit)
+	default:
+		had = e.hallDownStops.has(bit)
+		e.hallDownStops.clear(bit)
+	}
+	if !had {
+		return false
+	}
+	if !e.hasStopAt(bit) {
+		e.dropVIP(r.Floor)
+	}
+	e.reconsiderDirection()
+	return true
+}
+
+// PressCabButton is a floor button in the cab; see Elevator.PressCabButton.
+func (e *MultiwordElevator) PressCabButton(floor int) error {
+	if e.CancelRequest(Request{Floor: floor, Type: CabCall}) {
+		return nil
+	}
+	return e.AddRequest(Request{Floor: floor, Type: CabCall})
+}
+
+// ReleaseHallCall drops the hall stop at floor in direction dir and returns
+// the passengers waiting for it, so another car can take over the call.
+func (e *MultiwordElevator) ReleaseHallCall(floor int, dir Direction) []*Passenger {
+	if floor < e.MinFloor || floor > e.MaxFloor {
+		return nil
+	}
+	if dir == DirUp {
+		e.hallUpStops.clear(e.idx(floor))
+	} else {
+		e.hallDownStops.clear(e.idx(floor))
+	}
+	if !e.hasStopAt(e.idx(floor)) {
+		e.dropVIP(floor)
+	}
+	e.reconsiderDirection()
+	return e.release(floor, dir)
+}
+
+// reconsiderDirection re-runs the direction choice of a moving car after its
+// stops changed.
+func (e *MultiwordElevator) reconsiderDirection() {
+	if e.parking || (e.State != StateMovingUp && e.State != StateMovingDown) {
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f == e.CurrentFloor {
+		// The VIP stop being run to went away; the next one is here.
+		e.opening = true
+		e.openDoor(DirIdle)
+		return
+	}
+	e.pickDirection()
+	if e.State == StateIdle && e.HasPendingRequests() {
+		e.opening = true
+		e.openDoor(DirIdle)
+	}
+}
+
+func (e *MultiwordElevator) Step() string {
+	return FormatStep(e.StepEvents())
+}
+
+// StepEvents advances the elevator by one time unit and returns what happened.
+func (e *MultiwordElevator) StepEvents() []StepEvent {
+	var opened []StepEvent
+	if e.opening {
+		e.opening = false
+		opened = []StepEvent{e.event(StepDoorOpened)}
+	}
+	return append(opened, e.step()...)
+}
+
+// step runs the car's state machine for one time unit.
+func (e *MultiwordElevator) step() []StepEvent {
+	if e.stalls(e.State, e.doorTimer) {
+		ev := e.event(StepFault)
+		ev.Fault = e.fault
+		return []StepEvent{ev}
+	}
+	dir := e.Direction
+	var evs []StepEvent
+	switch e.State {
+	case StateDoorOpen:
+		evs = e.stepDoorOpen()
+	case StateDoorHold:
+		evs = e.stepDoorHold()
+	case StateNudging:
+		evs = e.stepNudging()
+	case StateOverloaded:
+		evs = e.stepOverloaded()
+	case StateFireService:
+		evs = e.stepFireService()
+	case StateStranded:
+		evs = []StepEvent{e.event(StepStranded)}
+	case StateLowering:
+		evs = e.stepLowering()
+	case StateEmergencyStop:
+		evs = []StepEvent{e.event(StepOutOfService)}
+	case StateMovingUp:
+		evs = e.stepMove(DirUp)
+	case StateMovingDown:
+		evs = e.stepMove(DirDown)
+	default:
+		evs = e.stepIdle()
+	}
+	if e.Direction != dir {
+		evs = append(evs, e.event(StepDirectionChanged))
+	}
+	return evs
+}
+
+// event returns a StepEvent of the given kind at the car's current position.
+func (e *MultiwordElevator) event(kind StepKind) StepEvent {
+	return StepEvent{Kind: kind, CarID: e.ID, Floor: e.CurrentFloor, Direction: e.Direction}
+}
+
+func (e *MultiwordElevator) stepDoorOpen() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		ev := e.event(StepDoorHeld)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
+	}
+	return e.closeDoor()
+}
+
+// stepDoorHold keeps the door open for the hold button; see Elevator.stepDoorHold.
+func (e *MultiwordElevator) stepDoorHold() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		ev := e.event(StepHoldOpen)
+		ev.Remaining = e.doorTimer
+		return []StepEvent{ev}
+	}
+	return e.closeDoor()
+}
+
+// closeDoor closes the door unless the obstruction sensor reopens it; see
+// Elevator.closeDoor.
+func (e *MultiwordElevator) closeDoor() []StepEvent {
+	if e.obstructed {
+		if e.reopen() {
+			e.State, e.doorTimer = StateNudging, nudgeSteps
+			return []StepEvent{e.event(StepNudging)}
+		}
+		e.State, e.doorTimer = StateDoorOpen, doorOpenSteps
+		return []StepEvent{e.event(StepDoorReopened)}
+	}
+	return e.shutDoor()
+}
+
+// stepNudging closes the door slowly, ignoring the obstruction sensor.
+func (e *MultiwordElevator) stepNudging() []StepEvent {
+	e.doorTimer--
+	if e.doorTimer > 0 {
+		return []StepEvent{e.event(StepNudging)}
+	}
+	return e.shutDoor()
+}
+
+// shutDoor closes the door and decides the next action.
+func (e *MultiwordElevator) shutDoor() []StepEvent {
+	e.reopens = 0
+	e.State = StateIdle
+	if e.antiNuisance && len(e.occupants) == 0 && e.terminal(e.CurrentFloor) {
+		cancelCabCalls(e)
+	}
+	e.pickDirection()
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+// stepOverloaded sounds the overload alarm with the door held open. Each step
+// the last passenger to board here steps back off and waits for the car's
+// next pass; once the car is within capacity the door re-times and closes
+// as usual.
+func (e *MultiwordElevator) stepOverloaded() []StepEvent {
+	p := e.stepOff(e.CurrentFloor)
+	if p != nil {
+		e.addRequest(p.HallRequest())
+	}
+	if p == nil || !e.overloaded() {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
+	return []StepEvent{e.event(StepOverloadAlarm)}
+}
+
+// stepLowering runs a car on emergency power down to its lowering floor.
+func (e *MultiwordElevator) stepLowering() []StepEvent {
+	var evs []StepEvent
+	if e.CurrentFloor > e.lowerTo {
+		e.CurrentFloor--
+		evs = append(evs, e.event(StepMoved))
+		if e.CurrentFloor > e.lowerTo {
+			return evs
+		}
+	}
+	e.emergencyStop()
+	return append(evs, e.event(StepEmergencyStop))
+}
+
+// stepFireService holds the door open in fire service until a Phase II cab call.
+func (e *MultiwordElevator) stepFireService() []StepEvent {
+	if !e.firefighter || !e.HasPendingRequests() {
+		return []StepEvent{e.event(StepFireService)}
+	}
+	e.State = StateIdle
+	e.pickDirection()
+	return []StepEvent{e.event(StepDoorClosed)}
+}
+
+func (e *MultiwordElevator) stepMove(dir Direction) []StepEvent {
+	if dir == DirUp {
+		e.CurrentFloor++
+	} else {
+		e.CurrentFloor--
+	}
+
+	evs := []StepEvent{e.event(StepMoved)}
+	if e.mode == EmergencySeismic {
+		// Seismic stop at the first floor reached.
+		e.emergencyStop()
+		return append(evs, e.event(StepEmergencyStop))
+	}
+	if e.recalling() {
+		// Fire recall: run non-stop to the recall floor.
+		if e.CurrentFloor == e.recallFloor {
+			e.parkRecalled()
+			evs = append(evs, e.event(StepRecalled))
+		}
+		return evs
+	}
+	if e.parking {
+		if e.CurrentFloor == e.parkFloor {
+			e.stopParking()
+			evs = append(evs, e.event(StepParked))
+		}
+		return evs
+	}
+
+	if f, ok := e.vipTarget(); ok {
+		if e.CurrentFloor == f {
+			stop := e.event(StepStopped)
+			stop.VIP = true
+			e.openDoor(DirIdle)
+			evs = append(evs, stop, e.event(StepDoorOpened))
+		}
+		return evs
+	}
+
+	if e.shouldStop(dir) {
+		evs = append(evs, e.event(StepStopped))
+		e.openDoor(dir)
+		evs = append(evs, e.event(StepDoorOpened))
+	} else if e.WeightSensor() && e.hallStopAt(dir) {
+		evs = append(evs, e.event(StepOverloaded))
+	}
+	return evs
+}
+
+func (e *MultiwordElevator) stepIdle() []StepEvent {
+	e.pickDirection()
+	return []StepEvent{e.event(StepIdle)}
+}
+
+// hallStopAt reports whether the current floor has a hall stop in direction dir.
+func (e *MultiwordElevator) hallStopAt(dir Direction) bool {
+	bit := e.idx(e.CurrentFloor)
+	if dir == DirUp {
+		return e.hallUpStops.has(bit)
+	}
+	return e.hallDownStops.has(bit)
+}
+
+// shouldStop — O(1) for the current floor, O(n/64) at a possible turnaround.
+func (e *MultiwordElevator) shouldStop(dir Direction) bool {
+	bit := e.idx(e.CurrentFloor)
+
+	if e.WeightSensor() {
+		if dir == DirUp && !e.cabUpStops.has(bit) && e.hallUpStops.has(bit) {
+			return false
+		}
+		if dir == DirDown && !e.cabDownStops.has(bit) && e.hallDownStops.has(bit) {
+			return false
+		}
+	}
+
+	if dir == DirUp {
+		if e.cabUpStops.has(bit) || e.hallUpStops.has(bit) {
+			return true
+		}
+		if !e.hasStopsAbove() && (e.cabDownStops.has(bit) || e.hallDownStops.has(bit)) {
+			return true
+		}
+	} else {
+		if e.cabDownStops.has(bit) || e.hallDownStops.has(bit) {
+			return true
+		}
+		if !e.hasStopsBelow() && (e.cabUpStops.has(bit) || e.hallUpStops.has(bit)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *MultiwordElevator) openDoor(dir Direction) {
+	if e.State != StateDoorHold {
+		e.State = StateDoorOpen
+		e.doorTimer = doorOpenSteps
+	}
+	bit := e.idx(e.CurrentFloor)
+
+	up := dir == DirUp || dir == DirIdle || (dir == DirDown && !e.hasStopsBelow())
+	down := dir == DirDown || dir == DirIdle || (dir == DirUp && !e.hasStopsAbove())
+	if up {
+		e.cabUpStops.clear(bit)
+		e.hallUpStops.clear(bit)
+	}
+	if down {
+		e.cabDownStops.clear(bit)
+		e.hallDownStops.clear(bit)
+	}
+
+	e.alight(e.CurrentFloor)
+	for _, p := range e.board(e.CurrentFloor, up, down) {
+		e.addRequest(p.CabRequest())
+	}
+	e.vipServed(e.CurrentFloor)
+
+	if e.firefighter {
+		// Phase II: the door stays open until the next cab call.
+		e.State = StateFireService
+	} else if e.overloaded() {
+		e.State = StateOverloaded
+	}
+}
+
+func (e *MultiwordElevator) pickDirection() {
+	if e.recalling() {
+		e.runToRecall()
+		return
+	}
+	if f, ok := e.vipTarget(); ok && f != e.CurrentFloor {
+		if f > e.CurrentFloor {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+		} else {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+		}
+		return
+	}
+	switch e.Direction {
+	case DirUp:
+		if e.hasStopsAbove() {
+			e.State = StateMovingUp
+			return
+		}
+		if e.hasStopsBelow() {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+			return
+		}
+	case DirDown:
+		if e.hasStopsBelow() {
+			e.State = StateMovingDown
+			return
+		}
+		if e.hasStopsAbove() {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+			return
+		}
+	default:
+		if e.hasStopsAbove() {
+			e.Direction = DirUp
+			e.State = StateMovingUp
+			return
+		}
+		if e.hasStopsBelow() {
+			e.Direction = DirDown
+			e.State = StateMovingDown
+			return
+		}
+	}
+	e.Direction = DirIdle
+	e.State = StateIdle
+}
+
+// hasStopAt — O(1): check bit in the union of all stop sets.
+func (e *MultiwordElevator) hasStopAt(bit uint) bool {
+	return e.union(int(bit/64))&(1<<(bit%64)) != 0
+}
+
+// hasStopsAbove — O(n/64): mask the current word above the floor, then any
+// non-zero word after it.
+func (e *MultiwordElevator) hasStopsAbove() bool {
+	bit := e.idx(e.CurrentFloor)
+	w := int(bit / 64)
+	if e.union(w)&aboveMask(bit%64) != 0 {
+		return true
+	}
+	for i := w + 1; i < len(e.cabUpStops); i++ {
+		if e.union(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStopsBelow — O(n/64): mask the current word below the floor, then any
+// non-zero word before it.
+func (e *MultiwordElevator) hasStopsBelow() bool {
+	bit := e.idx(e.CurrentFloor)
+	w := int(bit / 64)
+	if e.union(w)&belowMask(bit%64) != 0 {
+		return true
+	}
+	for i := range w {
+		if e.union(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPendingRequests — O(n/64): any non-zero word in the union.
+func (e *MultiwordElevator) HasPendingRequests() bool {
+	for i := range e.cabUpStops {
+		if e.union(i) != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// PendingCount — O(n/64): popcount via bits.OnesCount64 per word. A cab and
+// a hall call at the same floor in the same direction are one stop.
+func (e *MultiwordElevator) PendingCount() int {
+	count := 0
+	for i := range e.cabUpStops {
+		count += bits.OnesCount64(e.cabUpStops[i]|e.hallUpStops[i]) +
+			bits.OnesCount64(e.cabDownStops[i]|e.hallDownStops[i])
+	}
+	return count
+}
+
+// StopsCabSnapshot returns the cab stop floors in each direction.
+func (e *MultiwordElevator) StopsCabSnapshot() (up []int, down []int) {
+	return e.cabUpStops.floors(e.MinFloor), e.cabDownStops.floors(e.MinFloor)
+}
+
+// StopsHallSnapshot returns the hall stop floors in each direction.
+func (e *MultiwordElevator) StopsHallSnapshot() (up []int, down []int) {
+	return e.hallUpStops.floors(e.MinFloor), e.hallDownStops.floors(e.MinFloor)
+}
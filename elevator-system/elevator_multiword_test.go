@@ -0,0 +1,155 @@
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+// runMultiwordUntilIdle drives the multi-word elevator until idle.
+func runMultiwordUntilIdle(e *MultiwordElevator, maxSteps int) []int {
+	var stops []int
+	for range maxSteps {
+		e.Step()
+		if e.State == StateDoorOpen && e.doorTimer == doorOpenSteps {
+			stops = append(stops, e.CurrentFloor)
+		}
+		if e.State == StateIdle && !e.HasPendingRequests() {
+			break
+		}
+	}
+	return stops
+}
+
+func TestMultiwordElevator_WordCount(t *testing.T) {
+	tests := []struct {
+		floors int
+		want   int
+	}{
+		{1, 1}, {64, 1}, {65, 2}, {128, 2}, {129, 3},
+	}
+	for _, tt := range tests {
+		if got := len(NewMultiwordElevator(1, 1, tt.floors).cabUpStops); got != tt.want {
+			t.Errorf("%d floors: expected %d words, got %d", tt.floors, tt.want, got)
+		}
+	}
+}
+
+func TestMultiwordElevator_SCANOrder_AcrossWords(t *testing.T) {
+	e := NewMultiwordElevator(1, 1, 200)
+	e.CurrentFloor = 60
+	e.Direction = DirUp
+
+	// 64 and 65 sit on either side of the first word boundary (bits 63 / 64).
+	for _, f := range []int{130, 65, 2, 64, 129} {
+		e.AddRequest(Request{Floor: f, Type: CabCall})
+	}
+
+	stops := runMultiwordUntilIdle(e, 1000)
+
+	expected := []int{64, 65, 129, 130, 2}
+	if !intSliceEqual(stops, expected) {
+		t.Errorf("expected %v, got %v", expected, stops)
+	}
+}
+
+func TestMultiwordElevator_StopsBeyondFirstWord(t *testing.T) {
+	e := NewMultiwordElevator(1, 1, 200)
+	e.CurrentFloor = 100
+
+	e.AddRequest(Request{Floor: 180, Direction: DirDown, Type: HallCall})
+	e.AddRequest(Request{Floor: 10, Type: CabCall})
+	if !e.hasStopsAbove() || !e.hasStopsBelow() {
+		t.Fatal("expected stops on both sides of floor 100")
+	}
+	if got := e.PendingCount(); got != 2 {
+		t.Errorf("expected PendingCount=2, got %d", got)
+	}
+	if _, down := e.StopsHallSnapshot(); !intSliceEqual(down, []int{180}) {
+		t.Errorf("expected hall down stop [180], got %v", down)
+	}
+
+	e.CurrentFloor = 180
+	if e.hasStopsAbove() {
+		t.Error("expected no stops above the top stop")
+	}
+}
+
+func TestMultiwordElevator_OutOfRange(t *testing.T) {
+	e := NewMultiwordElevator(1, 1, 100)
+	e.AddRequest(Request{Floor: 101, Type: CabCall})
+
+	if e.HasPendingRequests() {
+		t.Error("should not accept out-of-range request")
+	}
+}
+
+// --- Verify the implementations produce identical results ---
+
+func TestMultiwordElevator_MatchesOtherImpls(t *testing.T) {
+	requests := []Request{
+		{Floor: 7, Type: CabCall},
+		{Floor: 3, Type: CabCall},
+		{Floor: 5, Direction: DirUp, Type: HallCall},
+		{Floor: 9, Type: CabCall},
+	}
+
+	boolElev := NewElevator(1, 1, 10)
+	bitmaskElev := NewBitmaskElevator(1, 1, 10)
+	multiwordElev := NewMultiwordElevator(1, 1, 10)
+
+	for _, r := range requests {
+		boolElev.AddRequest(r)
+		bitmaskElev.AddRequest(r)
+		multiwordElev.AddRequest(r)
+	}
+
+	boolStops := runUntilIdle(boolElev, 50)
+	bitmaskStops := runBitmaskUntilIdle(bitmaskElev, 50)
+	multiwordStops := runMultiwordUntilIdle(multiwordElev, 50)
+
+	if !intSliceEqual(boolStops, multiwordStops) {
+		t.Errorf("multiword diverged from []bool:\n  []bool:    %v\n  multiword: %v", boolStops, multiwordStops)
+	}
+	if !intSliceEqual(bitmaskStops, multiwordStops) {
+		t.Errorf("multiword diverged from bitmask:\n  bitmask:   %v\n  multiword: %v", bitmaskStops, multiwordStops)
+	}
+}
+
+func TestMultiwordElevator_MatchesOtherImpls_TallBuilding(t *testing.T) {
+	// The bitmask car stops at 64 floors; compare against []bool and bitset
+	// on a 200-floor tower with requests arriving while the cars run.
+	rng := rand.New(rand.NewPCG(1, 2))
+	cars := []CarModel{
+		NewElevator(1, 1, 200),
+		NewBitsetElevator(1, 1, 200),
+		NewMultiwordElevator(1, 1, 200),
+	}
+	trace := make([][]string, len(cars))
+
+	for step := range 3000 {
+		if step%5 == 0 {
+			r := Request{Floor: 1 + rng.IntN(200), Type: CabCall}
+			if rng.IntN(2) == 0 {
+				r = Request{Floor: r.Floor, Direction: DirUp, Type: HallCall}
+				if rng.IntN(2) == 0 {
+					r.Direction = DirDown
+				}
+			}
+			for _, car := range cars {
+				car.AddRequest(r)
+			}
+		}
+		for i, car := range cars {
+			trace[i] = append(trace[i], car.Step())
+		}
+	}
+
+	for i, car := range cars[:2] {
+		for s := range trace[i] {
+			if trace[i][s] != trace[2][s] {
+				t.Fatalf("multiword diverged from %T at step %d:\n  %T: %s\n  multiword: %s",
+					car, s, car, trace[i][s], trace[2][s])
+			}
+		}
+	}
+}
@@ -180,17 +180,28 @@ func TestElevator_OutOfRangeRequest(t *testing.T) {
 
 // --- Level 4: Overweight behavior ---
 
+// boardRider puts a passenger on board heading to dest, as if it had boarded
+// at an earlier stop.
+func boardRider(car interface {
+	admit(p *Passenger)
+	AddRequest(r Request) error
+}, weight, dest int) *Passenger {
+	p := &Passenger{Destination: dest, Weight: weight}
+	car.admit(p)
+	car.AddRequest(p.CabRequest())
+	return p
+}
+
 func TestElevator_Overweight_SkipsHallStop(t *testing.T) {
 	e := NewElevator(1, 1, 10)
-	e.currentWeight = e.maxWeight // overweight
+	boardRider(e, e.Capacity().MaxWeight, 7) // full car
 
-	e.AddRequest(Request{Floor: 7, Type: CabCall})
 	e.AddRequest(Request{Floor: 5, Direction: DirUp, Type: HallCall})
 
 	stops := runUntilIdle(e, 100)
 
 	// Normal order would be [5, 7]. Overweight skips hall at 5 on the way up;
-	// after cab exit at 7 weight drops, so 5 is served on the return trip.
+	// after the rider exits at 7 weight drops, so 5 is served on the return trip.
 	expected := []int{7, 5}
 	if !intSliceEqual(stops, expected) {
 		t.Errorf("expected %v (hall at 5 skipped on first pass), got %v", expected, stops)
@@ -199,10 +210,8 @@ func TestElevator_Overweight_SkipsHallStop(t *testing.T) {
 
 func TestElevator_Overweight_StillServesCabStop(t *testing.T) {
 	e := NewElevator(1, 1, 10)
-	e.currentWeight = e.maxWeight
-
-	e.AddRequest(Request{Floor: 3, Type: CabCall})
-	e.AddRequest(Request{Floor: 6, Type: CabCall})
+	boardRider(e, e.Capacity().MaxWeight/2, 3)
+	boardRider(e, e.Capacity().MaxWeight/2, 6)
 
 	stops := runUntilIdle(e, 100)
 
@@ -214,9 +223,8 @@ func TestElevator_Overweight_StillServesCabStop(t *testing.T) {
 
 func TestElevator_Overweight_CabAndHallSameFloor(t *testing.T) {
 	e := NewElevator(1, 1, 10)
-	e.currentWeight = e.maxWeight
+	boardRider(e, e.Capacity().MaxWeight, 5)
 
-	e.AddRequest(Request{Floor: 5, Type: CabCall})
 	e.AddRequest(Request{Floor: 5, Direction: DirUp, Type: HallCall})
 
 	stops := runUntilIdle(e, 100)
@@ -229,16 +237,15 @@ func TestElevator_Overweight_CabAndHallSameFloor(t *testing.T) {
 
 func TestElevator_Overweight_WeightDrop_ResumesHallService(t *testing.T) {
 	e := NewElevator(1, 1, 10)
-	// Near max: after one cab passenger exits (-10), weight drops below max.
-	e.currentWeight = e.maxWeight + passengerWeight - 1 // 109
+	// Near max: after the light rider exits at 3, weight drops below max.
+	boardRider(e, passengerWeight, 3)
+	boardRider(e, e.Capacity().MaxWeight-1, 7) // total 109
 
-	e.AddRequest(Request{Floor: 3, Type: CabCall})
 	e.AddRequest(Request{Floor: 5, Direction: DirUp, Type: HallCall})
-	e.AddRequest(Request{Floor: 7, Type: CabCall})
 
 	stops := runUntilIdle(e, 100)
 
-	// At floor 3: cab exit → weight drops to 99 (< 100) → no longer overweight.
+	// At floor 3: rider exits → weight drops to 99 (< 100) → no longer overweight.
 	// At floor 5: hall stop now served normally.
 	// At floor 7: cab stop.
 	expected := []int{3, 5, 7}
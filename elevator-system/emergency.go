@@ -0,0 +1,106 @@
+package main
+
+import "errors"
+
+var ErrOutOfService = errors.New("car out of service after a seismic trigger or power failure")
+
+// EmergencyMode is the building emergency a car has stopped for.
+type EmergencyMode int
+
+const (
+	EmergencyNone         EmergencyMode = iota
+	EmergencySeismic                    // Seismic trigger: stop at the next floor in the direction of travel
+	EmergencyPowerFailure               // Normal power lost: wait for emergency power to reach a floor
+)
+
+func (m EmergencyMode) String() string {
+	switch m {
+	case EmergencySeismic:
+		return "Seismic"
+	case EmergencyPowerFailure:
+		return "PowerFailure"
+	default:
+		return "None"
+	}
+}
+
+// defaultEmergencyPowerCars is how many stranded cars the emergency power
+// supply runs at once unless WithEmergencyPower says otherwise.
+const defaultEmergencyPowerCars = 1
+
+// emergency is a car's part in seismic and power-failure operation. Both end
+// with the car out of service at a floor, door open, riders out, until the
+// building is reset.
+type emergency struct {
+	mode    EmergencyMode
+	lowerTo int // power failure: the floor a stranded car is lowered to
+}
+
+// Emergency reports the emergency the car has stopped for.
+func (m *emergency) Emergency() EmergencyMode { return m.mode }
+
+// WithEmergencyPower sets how many stranded cars are lowered at once on
+// emergency power during a power failure (default 1).
+func WithEmergencyPower(cars int) DispatcherOption {
+	return func(d *Dispatcher) { d.EmergencyPowerCars = cars }
+}
+
+// SeismicTrigger stops every car for an earthquake. All stops are cancelled;
+// a moving car stops at the next floor in its direction, a standing car
+// opens its door where it is, and either lets its riders out. The cars stay
+// out of service until RestoreService.
+func (d *Dispatcher) SeismicTrigger() {
+	for _, car := range d.Elevators {
+		car.SeismicStop()
+	}
+	d.dropCalls()
+}
+
+// PowerFailure stops every car where it is on loss of normal power. A car
+// with its door open lets its riders out; every other car is stranded and
+// StepAll lowers them to the nearest floor on emergency power, in fleet
+// order, EmergencyPowerCars at a time. The cars stay out of service until
+// RestoreService.
+func (d *Dispatcher) PowerFailure() {
+	for _, car := range d.Elevators {
+		car.PowerFailure()
+	}
+	d.dropCalls()
+}
+
+// RestoreService ends seismic or power-failure operation: every car goes
+// back into service where it stands, and riders still on board in a car
+// that never reached a floor register their cab calls again.
+func (d *Dispatcher) RestoreService() {
+	for _, car := range d.Elevators {
+		car.ResetEmergency()
+	}
+}
+
+// tickEmergencyPower hands emergency power to stranded cars, in fleet order,
+// while fewer than EmergencyPowerCars are being lowered.
+func (d *Dispatcher) tickEmergencyPower() {
+	running := 0
+	for _, car := range d.Elevators {
+		if car.CurrentState() == StateLowering {
+			running++
+		}
+	}
+	for _, car := range d.Elevators {
+		if running >= d.EmergencyPowerCars {
+			return
+		}
+		if car.LowerToFloor() {
+			running++
+		}
+	}
+}
+
+// dropCalls forgets every outstanding hall call, destination group, VIP
+// reservation and transfer after an emergency cancelled the cars' stops.
+func (d *Dispatcher) dropCalls() {
+	d.calls = nil
+	d.destGroups = nil
+	d.reserved = nil
+	d.legs = nil
+}
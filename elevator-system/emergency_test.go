@@ -0,0 +1,160 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSeismicStop_StopsAtNextFloorInDirection(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			rider := NewPassenger(1, 1, 9, 0)
+			car.AddPassenger(rider) // boards at once
+			for car.Floor() < 3 {
+				car.Step()
+			}
+
+			car.SeismicStop()
+			if car.HasPendingRequests() || car.Emergency() != EmergencySeismic {
+				t.Fatalf("expected stops cancelled in seismic mode, got %d stops (%s)", car.PendingCount(), car.Emergency())
+			}
+			evs := car.StepEvents()
+			if car.Floor() != 4 || car.CurrentState() != StateEmergencyStop || evs[1].Kind != StepEmergencyStop {
+				t.Fatalf("expected emergency stop at 4, got %s at %d", car.CurrentState(), car.Floor())
+			}
+			if rider.State != PassengerEvacuated {
+				t.Errorf("expected the rider let out, got %s", rider.State)
+			}
+			if evs := car.StepEvents(); evs[0].Kind != StepOutOfService {
+				t.Errorf("expected out of service, got %v", evs[0].Kind)
+			}
+			if err := car.AddRequest(Request{Floor: 6, Type: CabCall}); !errors.Is(err, ErrOutOfService) {
+				t.Errorf("expected ErrOutOfService, got %v", err)
+			}
+
+			car.ResetEmergency()
+			runCarUntilIdle(car, 10)
+			if err := car.AddRequest(Request{Floor: 6, Type: CabCall}); err != nil {
+				t.Errorf("expected service restored, got %v", err)
+			}
+		})
+	}
+}
+
+func TestSeismicStop_StandingCarOpensWhereItIs(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.CurrentFloor = 5
+	e.SeismicStop()
+	if e.State != StateEmergencyStop || e.CurrentFloor != 5 {
+		t.Errorf("expected door open at 5, got %s at %d", e.State, e.CurrentFloor)
+	}
+}
+
+// newStrandingDispatcher builds three cars caught by an emergency: car 1
+// running down past 6, car 2 running up past 4, car 3 loading at 1.
+func newStrandingDispatcher(opts ...DispatcherOption) *Dispatcher {
+	d := NewDispatcher(3, 1, 10, opts...)
+	elevatorAt(d, 0).CurrentFloor = 9
+	elevatorAt(d, 0).AddRequest(Request{Floor: 2, Type: CabCall})
+	elevatorAt(d, 1).AddRequest(Request{Floor: 8, Type: CabCall})
+	for range 3 {
+		d.StepAll()
+	}
+	elevatorAt(d, 2).AddRequest(Request{Floor: 1, Type: CabCall})
+	return d
+}
+
+func TestDispatcher_SeismicTrigger(t *testing.T) {
+	d := newStrandingDispatcher()
+	d.SeismicTrigger()
+	if car := d.Dispatch(Request{Floor: 3, Direction: DirUp, Type: HallCall}); car != nil {
+		t.Errorf("expected no car after a seismic trigger, got %d", car.CarID())
+	}
+	d.StepAll()
+	want := map[int]int{1: 5, 2: 5, 3: 1}
+	for _, car := range d.Elevators {
+		if car.CurrentState() != StateEmergencyStop || car.Floor() != want[car.CarID()] {
+			t.Errorf("car %d: expected stopped at %d, got %s at %d",
+				car.CarID(), want[car.CarID()], car.CurrentState(), car.Floor())
+		}
+	}
+
+	d.RestoreService()
+	if car := d.Dispatch(Request{Floor: 3, Direction: DirUp, Type: HallCall}); car == nil {
+		t.Error("expected a car once service is restored")
+	}
+}
+
+func TestDispatcher_PowerFailureLowersCarsOneAtATime(t *testing.T) {
+	d := newStrandingDispatcher()
+	d.PowerFailure()
+	if d.Elevators[2].CurrentState() != StateEmergencyStop {
+		t.Errorf("expected the car with its door open to stop at once, got %s", d.Elevators[2].CurrentState())
+	}
+
+	type stop struct{ step, car, floor int }
+	var stops []stop
+	step := 0
+	d.Events.Subscribe(func(ev StepEvent) {
+		if ev.Kind == StepEmergencyStop {
+			stops = append(stops, stop{step, ev.CarID, ev.Floor})
+		}
+	})
+	for step = 1; step <= 5; step++ {
+		d.StepAll()
+		lowering := 0
+		for _, car := range d.Elevators {
+			if car.CurrentState() == StateLowering {
+				lowering++
+			}
+		}
+		if lowering > 1 {
+			t.Errorf("step %d: %d cars on emergency power, want at most 1", step, lowering)
+		}
+	}
+
+	// Car 1 was between 6 and 5 going down; car 2 between 4 and 5 going up.
+	want := []stop{{1, 1, 5}, {2, 2, 4}}
+	if len(stops) != len(want) || stops[0] != want[0] || stops[1] != want[1] {
+		t.Errorf("expected evacuation %v, got %v", want, stops)
+	}
+}
+
+func TestDispatcher_PowerFailureParallelLowering(t *testing.T) {
+	d := newStrandingDispatcher(WithEmergencyPower(2))
+	d.PowerFailure()
+	d.StepAll()
+	for _, car := range d.Elevators {
+		if car.CurrentState() != StateEmergencyStop {
+			t.Errorf("car %d: expected every car at a floor after one step, got %s", car.CarID(), car.CurrentState())
+		}
+	}
+}
+
+func TestPowerFailure_RestoredBeforeLowering(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	car := d.Elevators[0]
+	rider := NewPassenger(1, 1, 7, 0)
+	d.DispatchPassenger(rider) // boards at once
+	for car.Floor() < 3 {
+		d.StepAll()
+	}
+
+	// Stepped on its own: StepAll would lower the car on emergency power.
+	d.PowerFailure()
+	for range 3 {
+		if evs := car.StepEvents(); evs[0].Kind != StepStranded || car.Floor() != 3 {
+			t.Fatalf("expected stranded at 3, got %v at %d", evs[0].Kind, car.Floor())
+		}
+	}
+
+	// Power comes back: the rider's trip resumes.
+	d.RestoreService()
+	for range 30 {
+		d.StepAll()
+	}
+	if rider.State != PassengerArrived || car.Floor() != 7 {
+		t.Errorf("expected the rider delivered to 7, got %s at %d", rider.State, car.Floor())
+	}
+}
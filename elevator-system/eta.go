@@ -0,0 +1,202 @@
+package main
+
+import "time"
+
+// ETAPolicy picks the car that would open its door for the request soonest.
+//
+// Where CostPolicy scores floor distance plus a penalty per pending stop,
+// ETAPolicy replays each car's remaining LOOK route with the request added
+// (see EstimateArrival), so a car two floors away with three stops on the
+// way loses to an idle car further off when that is actually faster.
+type ETAPolicy struct{}
+
+func (ETAPolicy) Select(_ *Dispatcher, cars []CarModel, r Request) CarModel {
+	best := cars[0]
+	bestETA := EstimateArrival(best, r)
+	for _, e := range cars[1:] {
+		if eta := EstimateArrival(e, r); eta < bestETA {
+			best, bestETA = e, eta
+		}
+	}
+	return best
+}
+
+// EstimateArrival estimates how long car would take to open its door for r
+// if r were added now, using the car's Kinematics.
+//
+// It plays the car's LOOK route forward floor by floor on a copy of its stop
+// sets: every stop before r costs the run to it plus a door cycle, with the
+// dwell scaled by the riders known to get on or off there (at least one).
+// A car with its door open, or sounding the overload alarm, first finishes
+// its current stop; a moving car is assumed to be at full speed. VIP
+// preemption and a full car skipping hall stops are not modelled.
+func EstimateArrival(car CarModel, r Request) time.Duration {
+	k := car.Kinematics()
+	pos, dir := car.Floor(), car.CurrentDirection()
+	state := car.CurrentState()
+	if car.Parking() {
+		state, dir = StateIdle, DirIdle
+	}
+	if r.Floor == pos && (state == StateIdle || state.loading()) {
+		return 0 // the door opens (or stays open) at once
+	}
+
+	rt := newRoute(car)
+	var t time.Duration
+	if state.doorOpen() {
+		t += k.DwellTime(0) + k.DoorClose
+	}
+	target := rt.add(r, pos, dir)
+	if state == StateIdle && dir == DirIdle {
+		// AddRequest starts an idle car toward the request.
+		dir = DirDown
+		if r.Floor > pos {
+			dir = DirUp
+		}
+	}
+
+	moving := state == StateMovingUp || state == StateMovingDown
+	run := 0 // floors since the car last stood still
+	for {
+		if dir = rt.pick(pos, dir); dir == DirIdle {
+			return t
+		}
+		if dir == DirUp {
+			pos++
+		} else {
+			pos--
+		}
+		run++
+		if !rt.shouldStop(pos, dir) {
+			continue
+		}
+
+		travel := k.TravelTime(run)
+		if moving {
+			// Already at speed: only the braking half of the overhead remains.
+			travel = max(travel-seconds(k.accelTime(k.MaxSpeed)/2), time.Duration(run)*k.FloorTime())
+			moving = false
+		}
+		t += travel + k.DoorOpen
+		run = 0
+
+		rt.serve(pos, dir)
+		if !rt.has(target) {
+			return t
+		}
+		t += k.DwellTime(max(1, rt.transfers[pos])) + k.DoorClose
+	}
+}
+
+// routeStop is one entry in a route: a floor in the up or down stop set.
+type routeStop struct {
+	floor int
+	dir   Direction
+}
+
+// route is a copy of a car's stops, merged by travel direction as the car's
+// shouldStop and openDoor see them, that EstimateArrival plays forward.
+type route struct {
+	stops     map[routeStop]bool
+	transfers map[int]int // riders getting off plus passengers getting on, by floor
+}
+
+func newRoute(car CarModel) route {
+	rt := route{stops: make(map[routeStop]bool), transfers: make(map[int]int)}
+	cabUp, cabDown := car.StopsCabSnapshot()
+	hallUp, hallDown := car.StopsHallSnapshot()
+	for _, fs := range [][]int{cabUp, hallUp} {
+		for _, f := range fs {
+			rt.stops[routeStop{f, DirUp}] = true
+		}
+	}
+	for _, fs := range [][]int{cabDown, hallDown} {
+		for _, f := range fs {
+			rt.stops[routeStop{f, DirDown}] = true
+		}
+	}
+	for _, p := range car.Occupants() {
+		rt.transfers[p.Destination]++
+	}
+	for _, p := range car.Waiting() {
+		rt.transfers[p.Origin]++
+	}
+	return rt
+}
+
+// add places r in the stop set the car would put it in and returns that stop.
+// A cab call for the floor a moving car is leaving is served on the way back.
+func (rt route) add(r Request, pos int, carDir Direction) routeStop {
+	dir := r.Direction
+	if r.Type == CabCall {
+		dir = r.cabDirection(pos)
+	}
+	if dir == DirIdle {
+		dir = DirUp
+		if carDir == DirUp {
+			dir = DirDown
+		}
+	}
+	s := routeStop{r.Floor, dir}
+	rt.stops[s] = true
+	return s
+}
+
+func (rt route) has(s routeStop) bool { return rt.stops[s] }
+
+func (rt route) above(pos int) bool {
+	for s := range rt.stops {
+		if s.floor > pos {
+			return true
+		}
+	}
+	return false
+}
+
+func (rt route) below(pos int) bool {
+	for s := range rt.stops {
+		if s.floor < pos {
+			return true
+		}
+	}
+	return false
+}
+
+// pick mirrors the cars' pickDirection: keep going while there are stops
+// ahead, else reverse, else idle.
+func (rt route) pick(pos int, dir Direction) Direction {
+	switch {
+	case dir == DirDown && rt.below(pos):
+		return DirDown
+	case rt.above(pos):
+		return DirUp
+	case rt.below(pos):
+		return DirDown
+	default:
+		return DirIdle
+	}
+}
+
+// shouldStop mirrors the cars' shouldStop: stop for the travel direction's
+// set, or for the other set at the turnaround.
+func (rt route) shouldStop(pos int, dir Direction) bool {
+	if rt.stops[routeStop{pos, dir}] {
+		return true
+	}
+	if dir == DirUp {
+		return !rt.above(pos) && rt.stops[routeStop{pos, DirDown}]
+	}
+	return !rt.below(pos) && rt.stops[routeStop{pos, DirUp}]
+}
+
+// serve mirrors openDoor's clearing: the travel direction's stop, plus the
+// other direction's at the turnaround.
+func (rt route) serve(pos int, dir Direction) {
+	delete(rt.stops, routeStop{pos, dir})
+	if dir == DirUp && !rt.above(pos) {
+		delete(rt.stops, routeStop{pos, DirDown})
+	}
+	if dir == DirDown && !rt.below(pos) {
+		delete(rt.stops, routeStop{pos, DirUp})
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEstimateArrival_IdleCar(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 20)
+			k := car.Kinematics()
+			if got := EstimateArrival(car, Request{Floor: 1, Direction: DirUp, Type: HallCall}); got != 0 {
+				t.Errorf("expected 0 at the car's floor, got %v", got)
+			}
+			want := k.TravelTime(7) + k.DoorOpen
+			if got := EstimateArrival(car, Request{Floor: 8, Direction: DirDown, Type: HallCall}); got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+		})
+	}
+}
+
+func TestEstimateArrival_MatchesTimedSimulator(t *testing.T) {
+	d := NewDispatcher(1, 1, 20)
+	eta := EstimateArrival(d.Elevators[0], Request{Floor: 12, Direction: DirDown, Type: HallCall})
+
+	s := NewSimulator(d, time.Second)
+	s.Timed = true
+	p := s.AddPassenger(0, 12, 1)
+	s.Run()
+	if !durationNear(p.BoardTime, eta) {
+		t.Errorf("estimated %v, simulated car arrived at %v", eta, p.BoardTime)
+	}
+}
+
+func TestEstimateArrival_FollowsLOOKRoute(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 20)
+			k := car.Kinematics()
+			car.AddRequest(Request{Floor: 9, Type: CabCall})
+			for range 4 {
+				car.Step() // moving up through floor 5
+			}
+
+			// A down call at 3 is behind the car: it first runs on to 9,
+			// stops there, then comes back down.
+			cruise := max(k.TravelTime(4)-seconds(k.accelTime(k.MaxSpeed)/2), 4*k.FloorTime())
+			want := cruise + k.DoorOpen + k.DwellTime(1) + k.DoorClose + k.TravelTime(6) + k.DoorOpen
+			if got := EstimateArrival(car, Request{Floor: 3, Direction: DirDown, Type: HallCall}); got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+
+			// An up call at 7 is on the way: no stop before it.
+			want = max(k.TravelTime(2)-seconds(k.accelTime(k.MaxSpeed)/2), 2*k.FloorTime()) + k.DoorOpen
+			if got := EstimateArrival(car, Request{Floor: 7, Direction: DirUp, Type: HallCall}); got != want {
+				t.Errorf("expected %v, got %v", want, got)
+			}
+			if up, _ := car.StopsHallSnapshot(); len(up) != 0 {
+				t.Errorf("estimate must not touch the car's stops, got %v", up)
+			}
+		})
+	}
+}
+
+func TestEstimateArrival_DwellScalesWithRiders(t *testing.T) {
+	car := NewElevator(1, 1, 20)
+	k := car.Kinematics()
+	for i := range 3 {
+		p := NewPassenger(i+1, 1, 5, 0)
+		car.AddPassenger(p) // boards at once: the door is at floor 1
+	}
+	runCarUntilDoorCloses(car)
+
+	// All three riders get off at 5 on the way to an up call at 10.
+	got := EstimateArrival(car, Request{Floor: 10, Direction: DirUp, Type: HallCall})
+	cruise := max(k.TravelTime(4)-seconds(k.accelTime(k.MaxSpeed)/2), 4*k.FloorTime())
+	want := cruise + k.DoorOpen + k.DwellTime(3) + k.DoorClose + k.TravelTime(5) + k.DoorOpen
+	if got != want {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+}
+
+// runCarUntilDoorCloses steps car until it leaves the door-open state.
+func runCarUntilDoorCloses(car CarModel) {
+	for car.CurrentState() == StateDoorOpen {
+		car.Step()
+	}
+}
+
+func TestETAPolicy_PrefersFasterOverNearer(t *testing.T) {
+	// Car 1 idles 7 floors away; car 2 is 2 floors away heading up but has
+	// three stops to make first.
+	d := NewDispatcher(2, 1, 20, WithPolicy(ETAPolicy{}))
+	busy := elevatorAt(d, 1)
+	busy.CurrentFloor = 6
+	for _, f := range []int{7, 9, 10} {
+		busy.AddRequest(Request{Floor: f, Type: CabCall})
+	}
+
+	if chosen := d.Dispatch(Request{Floor: 8, Direction: DirUp, Type: HallCall}); chosen.CarID() != 1 {
+		t.Errorf("expected idle car 1, got %d", chosen.CarID())
+	}
+	// With no stops in the way the nearer car wins.
+	if chosen := d.Dispatch(Request{Floor: 7, Direction: DirUp, Type: HallCall}); chosen.CarID() != 2 {
+		t.Errorf("expected car 2 already stopping at 7, got %d", chosen.CarID())
+	}
+}
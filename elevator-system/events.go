@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// StepKind identifies something a car did during one Step.
+type StepKind int
+
+const (
+	StepMoved            StepKind = iota // Car moved one floor
+	StepStopped                          // Car stopped to serve the floor
+	StepDoorOpened                       // Door opened
+	StepDoorHeld                         // Door stays open; Remaining steps until it closes
+	StepDoorClosed                       // Door closed; Direction is the next travel direction
+	StepDirectionChanged                 // Travel direction changed; Direction is the new one
+	StepOverloaded                       // Full car passed a hall stop it could not take
+	StepOverloadAlarm                    // Car over capacity: door held open, last to board steps off
+	StepParked                           // Car reached its parking floor
+	StepRecalled                         // Car reached the fire recall floor: riders out, door held open
+	StepFireService                      // Door held open in fire service
+	StepStranded                         // Car stopped without power, waiting for emergency power
+	StepEmergencyStop                    // Car stopped at a floor for a seismic trigger or power failure: riders out, door open
+	StepOutOfService                     // Door held open, out of service after an emergency stop
+	StepFault                            // A fault kept the car from acting; Fault says which
+	StepDoorReopened                     // Door about to close reopened for an obstruction
+	StepHoldOpen                         // Door held open by the hold button; Remaining steps of hold left
+	StepNudging                          // Door closing slowly with the buzzer after repeated reopens
+	StepIdle                             // Car was idle at the start of the step
+)
+
+func (k StepKind) String() string {
+	switch k {
+	case StepMoved:
+		return "Moved"
+	case StepStopped:
+		return "Stopped"
+	case StepDoorOpened:
+		return "DoorOpened"
+	case StepDoorHeld:
+		return "DoorHeld"
+	case StepDoorClosed:
+		return "DoorClosed"
+	case StepDirectionChanged:
+		return "DirectionChanged"
+	case StepOverloaded:
+		return "Overloaded"
+	case StepOverloadAlarm:
+		return "OverloadAlarm"
+	case StepParked:
+		return "Parked"
+	case StepRecalled:
+		return "Recalled"
+	case StepFireService:
+		return "FireService"
+	case StepStranded:
+		return "Stranded"
+	case StepEmergencyStop:
+		return "EmergencyStop"
+	case StepOutOfService:
+		return "OutOfService"
+	case StepFault:
+		return "Fault"
+	case StepDoorReopened:
+		return "DoorReopened"
+	case StepHoldOpen:
+		return "HoldOpen"
+	case StepNudging:
+		return "Nudging"
+	default:
+		return "Idle"
+	}
+}
+
+// StepEvent is one typed fact about a car step. A single Step can produce
+// several, e.g. Moved, Stopped, DoorOpened.
+type StepEvent struct {
+	Kind      StepKind
+	CarID     int
+	Floor     int           // floor after the event
+	Direction Direction     // travel direction after the event
+	Remaining int           // StepDoorHeld: steps until the door closes; StepHoldOpen: steps of hold left
+	VIP       bool          // StepStopped: express stop for a VIP call
+	Fault     Fault         // StepFault: the fault holding the car
+	At        time.Duration // stamped by the publisher: time since the run started
+}
+
+// stepBody returns the events that say what a step did, past the leading
+// StepDoorOpened of a door the car opened between steps, and whether there
+// was one.
+func stepBody(evs []StepEvent) (body []StepEvent, opened bool) {
+	if len(evs) > 1 && evs[0].Kind == StepDoorOpened {
+		return evs[1:], true
+	}
+	return evs, false
+}
+
+// FormatStep renders one step's events as the one-line description Step
+// returns, e.g. "Elevator 1: moved to floor 5 [STOP — door opening]".
+func FormatStep(evs []StepEvent) string {
+	if len(evs) == 0 {
+		return ""
+	}
+	evs, opened := stepBody(evs)
+	first := evs[0]
+	var msg string
+	switch first.Kind {
+	case StepDoorHeld:
+		msg = fmt.Sprintf("Elevator %d: door open at floor %d (closing in %d)",
+			first.CarID, first.Floor, first.Remaining)
+	case StepDoorClosed:
+		msg = fmt.Sprintf("Elevator %d: door closed at floor %d, direction=%s",
+			first.CarID, first.Floor, first.Direction)
+	case StepOverloadAlarm:
+		msg = fmt.Sprintf("Elevator %d: OVERLOADED at floor %d, door held open", first.CarID, first.Floor)
+	case StepFireService:
+		msg = fmt.Sprintf("Elevator %d: fire service at floor %d, door held open", first.CarID, first.Floor)
+	case StepStranded:
+		msg = fmt.Sprintf("Elevator %d: stranded at floor %d, waiting for emergency power", first.CarID, first.Floor)
+	case StepEmergencyStop:
+		msg = fmt.Sprintf("Elevator %d: emergency stop at floor %d, door open", first.CarID, first.Floor)
+	case StepOutOfService:
+		msg = fmt.Sprintf("Elevator %d: out of service at floor %d, door open", first.CarID, first.Floor)
+	case StepFault:
+		msg = fmt.Sprintf("Elevator %d: FAULT (%s) at floor %d", first.CarID, first.Fault, first.Floor)
+	case StepDoorReopened:
+		msg = fmt.Sprintf("Elevator %d: door reopened at floor %d (obstruction)", first.CarID, first.Floor)
+	case StepHoldOpen:
+		msg = fmt.Sprintf("Elevator %d: door held open at floor %d (hold, %d left)",
+			first.CarID, first.Floor, first.Remaining)
+	case StepNudging:
+		msg = fmt.Sprintf("Elevator %d: NUDGING at floor %d, door closing slowly", first.CarID, first.Floor)
+	case StepIdle:
+		if first.Direction == DirIdle {
+			msg = fmt.Sprintf("Elevator %d: idle at floor %d", first.CarID, first.Floor)
+		} else {
+			msg = fmt.Sprintf("Elevator %d: idle at floor %d, starting %s",
+				first.CarID, first.Floor, first.Direction)
+		}
+	default:
+		msg = fmt.Sprintf("Elevator %d: moved to floor %d", first.CarID, first.Floor)
+	}
+	if opened {
+		msg += " [door opened]"
+	}
+	for _, ev := range evs[1:] {
+		switch ev.Kind {
+		case StepStopped:
+			if ev.VIP {
+				msg += " [VIP STOP — door opening]"
+			} else {
+				msg += " [STOP — door opening]"
+			}
+		case StepParked:
+			msg += " [parked]"
+		case StepRecalled:
+			msg += " [FIRE RECALL — door open]"
+		case StepEmergencyStop:
+			msg += " [EMERGENCY STOP — door open]"
+		}
+	}
+	return msg
+}
+
+// EventBus fans step events out to subscribers. It is safe for concurrent
+// use; subscribers run synchronously on the publishing goroutine, in
+// subscription order, and must not block.
+type EventBus struct {
+	mu    sync.Mutex
+	subs  []subscriber
+	next  int
+	start time.Time
+}
+
+type subscriber struct {
+	id int
+	fn func(StepEvent)
+}
+
+// NewEventBus creates a bus whose Publish stamps events with the time since now.
+func NewEventBus() *EventBus {
+	return &EventBus{start: time.Now()}
+}
+
+// Subscribe registers fn for every published event. Call the returned
+// function to unsubscribe.
+func (b *EventBus) Subscribe(fn func(StepEvent)) (unsubscribe func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.next++
+	id := b.next
+	b.subs = append(b.subs, subscriber{id, fn})
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, s := range b.subs {
+			if s.id == id {
+				b.subs = append(b.subs[:i:i], b.subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish stamps evs with the wall-clock time since the bus was created and
+// delivers them. A nil bus drops events.
+func (b *EventBus) Publish(evs []StepEvent) {
+	if b == nil {
+		return
+	}
+	b.PublishAt(time.Since(b.start), evs)
+}
+
+// PublishAt delivers evs stamped with at, for callers that own the clock
+// (e.g. Simulator time).
+func (b *EventBus) PublishAt(at time.Duration, evs []StepEvent) {
+	if b == nil || len(evs) == 0 {
+		return
+	}
+	b.mu.Lock()
+	subs := b.subs
+	b.mu.Unlock()
+	for _, ev := range evs {
+		ev.At = at
+		for _, s := range subs {
+			s.fn(ev)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func kinds(evs []StepEvent) []StepKind {
+	out := make([]StepKind, len(evs))
+	for i, ev := range evs {
+		out[i] = ev.Kind
+	}
+	return out
+}
+
+func TestStepEvents_StopSequence(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(7, 1, 10)
+			car.AddRequest(Request{Floor: 3, Type: CabCall})
+
+			want := [][]StepKind{
+				{StepMoved},
+				{StepMoved, StepStopped, StepDoorOpened},
+				{StepDoorHeld},
+				{StepDoorClosed, StepDirectionChanged},
+				{StepIdle},
+			}
+			for i, w := range want {
+				evs := car.StepEvents()
+				if got := kinds(evs); !slices.Equal(got, w) {
+					t.Fatalf("step %d: got %v, want %v", i+1, got, w)
+				}
+				for _, ev := range evs {
+					if ev.CarID != 7 {
+						t.Errorf("step %d: event %s has car %d, want 7", i+1, ev.Kind, ev.CarID)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestStepEvents_CallAtOwnFloor(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 1, Direction: DirUp, Type: HallCall}) // door opens at once
+
+			want := [][]StepKind{
+				{StepDoorOpened, StepDoorHeld},
+				{StepDoorClosed},
+				{StepIdle},
+			}
+			for i, w := range want {
+				if got := kinds(car.StepEvents()); !slices.Equal(got, w) {
+					t.Fatalf("step %d: got %v, want %v", i+1, got, w)
+				}
+			}
+
+			// A call while the door is already open opens nothing new.
+			car.AddRequest(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+			car.StepEvents()
+			car.AddRequest(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+			if got := kinds(car.StepEvents()); !slices.Equal(got, []StepKind{StepDoorHeld}) {
+				t.Errorf("got %v, want DoorHeld", got)
+			}
+		})
+	}
+}
+
+func TestStepEvents_Overloaded(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			boardRider(car.(interface {
+				admit(p *Passenger)
+				AddRequest(r Request) error
+			}), 100, 7)
+			car.AddRequest(Request{Floor: 3, Direction: DirUp, Type: HallCall})
+
+			car.StepEvents()
+			evs := car.StepEvents()
+			if got := kinds(evs); !slices.Equal(got, []StepKind{StepMoved, StepOverloaded}) {
+				t.Fatalf("got %v, want Moved, Overloaded", got)
+			}
+			if evs[1].Floor != 3 {
+				t.Errorf("expected overload reported at 3, got %d", evs[1].Floor)
+			}
+		})
+	}
+}
+
+func TestFormatStep(t *testing.T) {
+	tests := []struct {
+		evs  []StepEvent
+		want string
+	}{
+		{[]StepEvent{{Kind: StepMoved, CarID: 1, Floor: 5}}, "Elevator 1: moved to floor 5"},
+		{[]StepEvent{{Kind: StepMoved, CarID: 1, Floor: 5}, {Kind: StepStopped}, {Kind: StepDoorOpened}},
+			"Elevator 1: moved to floor 5 [STOP — door opening]"},
+		{[]StepEvent{{Kind: StepMoved, CarID: 2, Floor: 8}, {Kind: StepStopped, VIP: true}, {Kind: StepDoorOpened}},
+			"Elevator 2: moved to floor 8 [VIP STOP — door opening]"},
+		{[]StepEvent{{Kind: StepMoved, CarID: 1, Floor: 4}, {Kind: StepParked}}, "Elevator 1: moved to floor 4 [parked]"},
+		{[]StepEvent{{Kind: StepDoorHeld, CarID: 1, Floor: 3, Remaining: 1}}, "Elevator 1: door open at floor 3 (closing in 1)"},
+		{[]StepEvent{{Kind: StepDoorClosed, CarID: 1, Floor: 3, Direction: DirDown}, {Kind: StepDirectionChanged}},
+			"Elevator 1: door closed at floor 3, direction=Down"},
+		{[]StepEvent{{Kind: StepDoorOpened, CarID: 1, Floor: 1}, {Kind: StepDoorHeld, CarID: 1, Floor: 1, Remaining: 1}},
+			"Elevator 1: door open at floor 1 (closing in 1) [door opened]"},
+		{[]StepEvent{{Kind: StepIdle, CarID: 3, Floor: 1}}, "Elevator 3: idle at floor 1"},
+		{[]StepEvent{{Kind: StepIdle, CarID: 3, Floor: 1, Direction: DirUp}, {Kind: StepDirectionChanged}},
+			"Elevator 3: idle at floor 1, starting Up"},
+	}
+	for _, tt := range tests {
+		if got := FormatStep(tt.evs); got != tt.want {
+			t.Errorf("FormatStep(%v) = %q, want %q", kinds(tt.evs), got, tt.want)
+		}
+	}
+}
+
+func TestEventBus_SubscribeUnsubscribe(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	var first, second []StepEvent
+	unsubscribe := d.Events.Subscribe(func(ev StepEvent) { first = append(first, ev) })
+	d.Events.Subscribe(func(ev StepEvent) { second = append(second, ev) })
+
+	d.Dispatch(Request{Floor: 2, Direction: DirUp, Type: HallCall})
+	d.StepAll()
+	if len(first) != 4 || len(second) != 4 {
+		t.Fatalf("expected 4 events (car 1: moved, stopped, door opened; car 2: idle), got %d and %d",
+			len(first), len(second))
+	}
+	if first[0].CarID != 1 || first[3].CarID != 2 {
+		t.Errorf("expected events in fleet order, got cars %d and %d", first[0].CarID, first[3].CarID)
+	}
+
+	unsubscribe()
+	d.StepAll()
+	if len(first) != 4 || len(second) != 6 {
+		t.Errorf("expected only the remaining subscriber notified, got %d and %d", len(first), len(second))
+	}
+}
+
+func TestSimulator_PublishesSimTime(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	var opened []StepEvent
+	d.Events.Subscribe(func(ev StepEvent) {
+		if ev.Kind == StepDoorOpened {
+			opened = append(opened, ev)
+		}
+	})
+	s := NewSimulator(d, time.Second)
+	s.AddPassenger(0, 4, 6)
+	s.Run()
+
+	if len(opened) != 2 {
+		t.Fatalf("expected door openings at 4 and 6, got %d", len(opened))
+	}
+	if opened[0].Floor != 4 || opened[0].At != 3*time.Second {
+		t.Errorf("expected door opened at floor 4 at 3s, got floor %d at %v", opened[0].Floor, opened[0].At)
+	}
+}
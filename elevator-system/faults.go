@@ -0,0 +1,238 @@
+package main
+
+import "math/rand/v2"
+
+// Fault is a malfunction that can strike a car.
+type Fault int
+
+const (
+	FaultNone          Fault = iota
+	FaultDoorJam             // Door will not close: the car stays at the floor it opened at
+	FaultStuck               // Car cannot run: it holds at the floor it last reached, riders trapped
+	FaultSensor              // Position sensor fails: the controller holds the car wherever it is
+	FaultMotorOverheat       // Thermal cut-out: the car cannot run until the motor cools down
+)
+
+func (f Fault) String() string {
+	switch f {
+	case FaultDoorJam:
+		return "DoorJam"
+	case FaultStuck:
+		return "Stuck"
+	case FaultSensor:
+		return "Sensor"
+	case FaultMotorOverheat:
+		return "MotorOverheat"
+	default:
+		return "None"
+	}
+}
+
+// motorCooldownSteps is how many steps an overheated motor takes to cool down.
+const motorCooldownSteps = 20
+
+// faultState is the fault a car is suffering, if any. A fault does not
+// report itself to the Dispatcher: the car just stops making progress until
+// the watchdog notices (see WithWatchdog).
+type faultState struct {
+	fault    Fault
+	cooldown int // FaultMotorOverheat: steps until the motor runs again
+}
+
+// Fault reports the fault the car is suffering.
+func (f *faultState) Fault() Fault { return f.fault }
+
+// InjectFault makes the car suffer fault in place of any other. A door jam
+// or a stuck car strikes the next time the car closes its door or runs.
+func (f *faultState) InjectFault(fault Fault) {
+	f.fault = fault
+	f.cooldown = 0
+	if fault == FaultMotorOverheat {
+		f.cooldown = motorCooldownSteps
+	}
+}
+
+// ClearFault repairs the car.
+func (f *faultState) ClearFault() { f.InjectFault(FaultNone) }
+
+// stalls reports whether the fault keeps a car in state s, with doorTimer
+// steps left on an open door, from doing anything this step. An overheated
+// motor cools one step per call and clears once it is cool.
+func (f *faultState) stalls(s ElevatorState, doorTimer int) bool {
+	moving := s == StateMovingUp || s == StateMovingDown
+	switch f.fault {
+	case FaultDoorJam:
+		return s.loading() && doorTimer <= 1
+	case FaultStuck:
+		return moving
+	case FaultSensor:
+		return true
+	case FaultMotorOverheat:
+		f.cooldown--
+		if f.cooldown <= 0 {
+			f.fault = FaultNone
+			return false
+		}
+		return moving
+	default:
+		return false
+	}
+}
+
+// FaultSource decides which faults strike the fleet. StepAll asks it about
+// every car once per tick, before the cars step; tick counts from 1.
+type FaultSource interface {
+	Fault(tick int, car CarModel) Fault
+}
+
+// FaultFunc adapts a function to FaultSource.
+type FaultFunc func(tick int, car CarModel) Fault
+
+func (f FaultFunc) Fault(tick int, car CarModel) Fault { return f(tick, car) }
+
+// ScheduledFault strikes car CarID at StepAll tick Tick.
+type ScheduledFault struct {
+	Tick  int
+	CarID int
+	Fault Fault
+}
+
+// FaultSchedule is a scripted FaultSource, e.g. to replay an incident.
+type FaultSchedule []ScheduledFault
+
+func (s FaultSchedule) Fault(tick int, car CarModel) Fault {
+	for _, f := range s {
+		if f.Tick == tick && f.CarID == car.CarID() {
+			return f.Fault
+		}
+	}
+	return FaultNone
+}
+
+// RandomFaults strikes each car with probability Rate per tick, with a fault
+// drawn uniformly. The same seed always yields the same faults.
+type RandomFaults struct {
+	Rate float64
+
+	rng *rand.Rand
+}
+
+// NewRandomFaults creates a seeded random fault source.
+func NewRandomFaults(rate float64, seed uint64) *RandomFaults {
+	return &RandomFaults{Rate: rate, rng: rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15))}
+}
+
+func (r *RandomFaults) Fault(int, CarModel) Fault {
+	if r.rng.Float64() >= r.Rate {
+		return FaultNone
+	}
+	return Fault(1 + r.rng.IntN(int(FaultMotorOverheat)))
+}
+
+// WithFaults injects the faults src decides on (default: none).
+func WithFaults(src FaultSource) DispatcherOption {
+	return func(d *Dispatcher) { d.Faults = src }
+}
+
+// WithWatchdog takes a car out of service once it has had work but made no
+// progress, neither changing floor nor state, for timeout StepAll ticks
+// (default 0: never).
+func WithWatchdog(timeout int) DispatcherOption {
+	return func(d *Dispatcher) { d.WatchdogTimeout = timeout }
+}
+
+// progress is what the watchdog last saw of a car.
+type progress struct {
+	floor   int
+	state   ElevatorState
+	stalled int // ticks with work but without a change
+}
+
+// injectFaults advances the tick count and strikes the cars the fault
+// source picks.
+func (d *Dispatcher) injectFaults() {
+	d.ticks++
+	if d.Faults == nil {
+		return
+	}
+	for _, car := range d.Elevators {
+		if f := d.Faults.Fault(d.ticks, car); f != FaultNone {
+			car.InjectFault(f)
+		}
+	}
+}
+
+// tickWatchdog fails every car that has had work without progress for
+// WatchdogTimeout ticks. Cars held by fire service or an emergency stop are
+// meant to stand still and are not watched, nor is a car sounding its
+// overload alarm: it puts a rider off every step until it can close. A door
+// held open or nudging closed gets its doorAllowance on top of the timeout.
+func (d *Dispatcher) tickWatchdog() {
+	if d.WatchdogTimeout <= 0 {
+		return
+	}
+	if d.watch == nil {
+		d.watch = make(map[CarModel]*progress)
+	}
+	for _, car := range d.Elevators {
+		if d.failed[car] {
+			continue
+		}
+		w, seen := d.watch[car]
+		busy := car.HasPendingRequests() || car.CurrentState() != StateIdle
+		held := car.FirePhase() != FireOff || car.Emergency() != EmergencyNone ||
+			car.CurrentState() == StateOverloaded
+		if !seen || !busy || held || car.Floor() != w.floor || car.CurrentState() != w.state {
+			d.watch[car] = &progress{floor: car.Floor(), state: car.CurrentState()}
+			continue
+		}
+		w.stalled++
+		if w.stalled >= d.WatchdogTimeout+doorAllowance(car) {
+			d.fail(car)
+		}
+	}
+}
+
+// doorAllowance is how many ticks car's door may stand still on purpose
+// beyond the watchdog timeout: a held door stays open up to MaxHoldSteps and
+// a nudging door takes nudgeSteps to close. A door jammed while held is still
+// caught once the allowance runs out.
+func doorAllowance(car CarModel) int {
+	switch car.CurrentState() {
+	case StateDoorHold:
+		return car.DoorConfig().MaxHoldSteps
+	case StateNudging:
+		return nudgeSteps
+	default:
+		return 0
+	}
+}
+
+// fail takes car out of service and hands its hall calls, with the
+// passengers waiting for them, to the cars still in service. Riders on board
+// stay with the car until it is repaired.
+func (d *Dispatcher) fail(car CarModel) {
+	if d.failed == nil {
+		d.failed = make(map[CarModel]bool)
+	}
+	d.failed[car] = true
+	delete(d.watch, car)
+	d.handOver(car)
+}
+
+// Failed reports whether the watchdog has taken car carID out of service.
+func (d *Dispatcher) Failed(carID int) bool {
+	car, err := d.car(carID)
+	return err == nil && d.failed[car]
+}
+
+// Repair clears car carID's fault and puts it back into service.
+func (d *Dispatcher) Repair(carID int) error {
+	car, err := d.car(carID)
+	if err != nil {
+		return err
+	}
+	car.ClearFault()
+	delete(d.failed, car)
+	return nil
+}
@@ -0,0 +1,218 @@
+package main
+
+import "testing"
+
+func TestFault_DoorJamHoldsDoorOpen(t *testing.T) {
+	d := NewDispatcher(1, 1, 10, WithFaults(FaultSchedule{{Tick: 1, CarID: 1, Fault: FaultDoorJam}}))
+	car := d.Elevators[0]
+	d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall}) // door opens at 1
+	car.AddRequest(Request{Floor: 5, Type: CabCall})
+	d.StepAll() // dwell
+	for range 5 {
+		if msg := d.StepAll()[0]; msg != "Elevator 1: FAULT (DoorJam) at floor 1" {
+			t.Fatalf("expected a door-jam fault, got %q", msg)
+		}
+	}
+	if car.Floor() != 1 || car.CurrentState() != StateDoorOpen {
+		t.Fatalf("expected door held open at 1, got %s at %d", car.CurrentState(), car.Floor())
+	}
+
+	d.Repair(1)
+	for range 20 {
+		d.StepAll()
+	}
+	if car.Floor() != 5 {
+		t.Errorf("expected the trip to resume to 5, got %d", car.Floor())
+	}
+}
+
+func TestFault_StuckBetweenFloors(t *testing.T) {
+	// Ticks 1-4 dwell, close and run up to 3; the car sticks leaving 3 on tick 5.
+	d := NewDispatcher(1, 1, 10, WithFaults(FaultSchedule{{Tick: 5, CarID: 1, Fault: FaultStuck}}))
+	car := d.Elevators[0]
+	rider := NewPassenger(1, 1, 8, 0)
+	d.DispatchPassenger(rider) // boards at once
+	for range 10 {
+		d.StepAll()
+	}
+	if car.Floor() != 3 || car.CurrentState() != StateMovingUp || rider.State != PassengerRiding {
+		t.Fatalf("expected the rider trapped at 3, got %s at %d (%s)", car.CurrentState(), car.Floor(), rider.State)
+	}
+
+	d.Repair(1)
+	for range 20 {
+		d.StepAll()
+	}
+	if rider.State != PassengerArrived {
+		t.Errorf("expected the rider delivered after repair, got %s", rider.State)
+	}
+}
+
+func TestFault_SensorHoldsIdleCar(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.InjectFault(FaultSensor)
+	e.AddRequest(Request{Floor: 4, Type: CabCall})
+	for range 5 {
+		e.Step()
+	}
+	if e.CurrentFloor != 1 {
+		t.Errorf("expected the car held at 1, got %d", e.CurrentFloor)
+	}
+}
+
+func TestFault_MotorOverheatCoolsDown(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.AddRequest(Request{Floor: 3, Type: CabCall})
+	e.Step() // up to 2
+	e.InjectFault(FaultMotorOverheat)
+	for range motorCooldownSteps - 1 {
+		e.Step()
+	}
+	if e.CurrentFloor != 2 || e.Fault() != FaultMotorOverheat {
+		t.Fatalf("expected the car cooling at 2, got %s at %d", e.Fault(), e.CurrentFloor)
+	}
+	e.Step()
+	if e.CurrentFloor != 3 || e.Fault() != FaultNone {
+		t.Errorf("expected the car running again once cool, got %s at %d", e.Fault(), e.CurrentFloor)
+	}
+}
+
+func TestRandomFaults_Deterministic(t *testing.T) {
+	a, b := NewRandomFaults(0.3, 7), NewRandomFaults(0.3, 7)
+	car := NewElevator(1, 1, 10)
+	struck := 0
+	for tick := range 200 {
+		fa, fb := a.Fault(tick, car), b.Fault(tick, car)
+		if fa != fb {
+			t.Fatalf("tick %d: same seed gave %s and %s", tick, fa, fb)
+		}
+		if fa != FaultNone {
+			struck++
+		}
+	}
+	if struck == 0 || struck == 200 {
+		t.Errorf("expected some faults at rate 0.3, got %d of 200", struck)
+	}
+}
+
+func TestDispatcher_WatchdogRescuesHallCalls(t *testing.T) {
+	d := NewDispatcher(2, 1, 10,
+		WithFaults(FaultSchedule{{Tick: 2, CarID: 1, Fault: FaultStuck}}),
+		WithWatchdog(3))
+	elevatorAt(d, 0).CurrentFloor = 3
+	rider := NewPassenger(1, 3, 9, 0)
+	waiter := NewPassenger(2, 6, 8, 0)
+	if car := d.DispatchPassenger(rider); car.CarID() != 1 {
+		t.Fatalf("expected car 1 to pick up at 3, got %d", car.CarID())
+	}
+	if car := d.DispatchPassenger(waiter); car.CarID() != 1 {
+		t.Fatalf("expected car 1 to take the call at 6, got %d", car.CarID())
+	}
+
+	for range 6 {
+		d.StepAll()
+	}
+	if !d.Failed(1) {
+		t.Fatalf("expected the watchdog to fail car 1, status:\n%s", d.Status())
+	}
+	calls := d.OutstandingCalls()
+	if len(calls) != 1 || calls[0].CarID != 2 {
+		t.Errorf("expected the call at 6 handed to car 2, got %v", calls)
+	}
+	if car := d.Dispatch(Request{Floor: 4, Direction: DirUp, Type: HallCall}); car == nil || car.CarID() != 2 {
+		t.Errorf("expected the failed car skipped, got %v", car)
+	}
+
+	for range 30 {
+		d.StepAll()
+	}
+	if waiter.State != PassengerArrived {
+		t.Errorf("expected the waiter rescued by car 2, got %s", waiter.State)
+	}
+	if rider.State != PassengerRiding {
+		t.Errorf("expected the rider still trapped in car 1, got %s", rider.State)
+	}
+
+	if err := d.Repair(1); err != nil {
+		t.Fatal(err)
+	}
+	for range 20 {
+		d.StepAll()
+	}
+	if d.Failed(1) || rider.State != PassengerArrived {
+		t.Errorf("expected car 1 back in service with its rider delivered, got %s", rider.State)
+	}
+}
+
+func TestDispatcher_WatchdogIgnoresNormalService(t *testing.T) {
+	d := NewDispatcher(3, 1, 20, WithWatchdog(3))
+	reqs := NewTrafficGenerator(TrafficInterFloor, 1, 20, 42).Requests(100)
+	for i := range 300 {
+		if i%3 == 0 {
+			d.Dispatch(reqs[i/3])
+		}
+		d.StepAll()
+	}
+	for _, car := range d.Elevators {
+		if d.Failed(car.CarID()) {
+			t.Errorf("car %d: failed without a fault", car.CarID())
+		}
+	}
+}
+
+func TestDispatcher_WatchdogIgnoresOverloadAlarm(t *testing.T) {
+	d := NewDispatcher(1, 1, 10, WithWatchdog(3))
+	car := elevatorAt(d, 0)
+	car.CurrentFloor = 3
+	var ps []*Passenger
+	for i := range 8 {
+		p := NewPassenger(i+1, 3, 9, 0)
+		ps = append(ps, p)
+		d.DispatchPassenger(p) // door open at 3: boards at once
+	}
+	// The car is derated while loading: the next call at 3 sets off an alarm
+	// that puts riders off one per step, longer than the watchdog timeout.
+	car.SetCapacity(Capacity{MaxWeight: 1000, MaxPassengers: 2})
+	d.DispatchPassenger(NewPassenger(9, 3, 9, 0))
+	if car.State != StateOverloaded {
+		t.Fatalf("expected the overload alarm, got %s", car.State)
+	}
+
+	for range 8 {
+		d.StepAll()
+	}
+	if d.Failed(1) {
+		t.Fatal("expected a car putting riders off not failed as stalled")
+	}
+	if len(car.Occupants()) != 2 || car.State == StateOverloaded {
+		t.Errorf("expected the alarm cleared with 2 riders, got %s with %d", car.State, len(car.Occupants()))
+	}
+}
+
+func TestDispatcher_WatchdogAllowsDoorHold(t *testing.T) {
+	d := NewDispatcher(1, 1, 10, WithWatchdog(5))
+	car := elevatorAt(d, 0)
+	d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+	car.AddRequest(Request{Floor: 5, Type: CabCall})
+	if !car.HoldDoor() {
+		t.Fatal("expected the door held")
+	}
+
+	for range 20 {
+		d.StepAll()
+	}
+	if d.Failed(1) || car.CurrentFloor != 5 {
+		t.Fatalf("expected the hold to run out and the car to reach 5, got floor %d (failed %v)", car.CurrentFloor, d.Failed(1))
+	}
+
+	// A door jammed while held is still caught once the hold would have ended.
+	car.AddRequest(Request{Floor: 5, Type: CabCall})
+	car.HoldDoor()
+	car.InjectFault(FaultDoorJam)
+	for range 20 {
+		d.StepAll()
+	}
+	if !d.Failed(1) {
+		t.Errorf("expected the jammed car failed, got %s", car.State)
+	}
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+var (
+	ErrFireService = errors.New("car in fire service")
+	ErrNotRecalled = errors.New("car not parked at its fire recall floor")
+)
+
+// FirePhase is a car's fire service mode.
+type FirePhase int
+
+const (
+	FireOff     FirePhase = iota // Normal service
+	FirePhaseI                   // Emergency recall: non-stop to the recall floor, parked there with the door open
+	FirePhaseII                  // Firefighter operation: only the car's own cab calls are honoured
+)
+
+func (p FirePhase) String() string {
+	switch p {
+	case FirePhaseI:
+		return "Phase I"
+	case FirePhaseII:
+		return "Phase II"
+	default:
+		return "Off"
+	}
+}
+
+// fireService is a car's part in fire service. Phase I is building-wide: the
+// fire alarm recalls every car to the recall floor. Phase II is per car: a
+// firefighter turns the key in a recalled car and drives it from the cab.
+type fireService struct {
+	recall      bool // Phase I in force for this car
+	firefighter bool // Phase II key switch on
+	recallFloor int
+}
+
+// FirePhase reports the car's fire service mode. Phase II wins over Phase I:
+// a firefighter keeps the car after the recall is reset.
+func (f *fireService) FirePhase() FirePhase {
+	switch {
+	case f.firefighter:
+		return FirePhaseII
+	case f.recall:
+		return FirePhaseI
+	default:
+		return FireOff
+	}
+}
+
+// recalling reports whether the car is under Phase I recall.
+func (f *fireService) recalling() bool { return f.FirePhase() == FirePhaseI }
+
+// refuses reports whether fire service rejects r: every request in Phase I,
+// all but cab calls in Phase II.
+func (f *fireService) refuses(r Request) bool {
+	switch f.FirePhase() {
+	case FirePhaseI:
+		return true
+	case FirePhaseII:
+		return r.Type != CabCall
+	default:
+		return false
+	}
+}
+
+// turnAway drops every passenger waiting for the car: their hall calls are
+// cancelled and they leave by the stairs.
+func (c *cabin) turnAway() {
+	for _, p := range c.waiting {
+		p.State = PassengerEvacuated
+	}
+	c.waiting = nil
+}
+
+// evacuate lets every rider out where the car stands, trip unfinished.
+func (c *cabin) evacuate() {
+	for _, p := range c.occupants {
+		p.State = PassengerEvacuated
+	}
+	c.occupants = nil
+	c.currentWeight = 0
+}
+
+// FireRecall starts Phase I emergency recall on every car. All stops are
+// cancelled and each car runs non-stop to floor, the designated recall floor,
+// where it lets its riders out and parks with the door open. A car that does
+// not stop at floor is recalled to its nearest served floor instead.
+//
+// Outstanding hall calls are dropped with the passengers waiting for them,
+// and Dispatch finds no car until ResetFireRecall.
+func (d *Dispatcher) FireRecall(floor int) {
+	for _, car := range d.Elevators {
+		car.FireRecall(recallFloorFor(car, floor))
+	}
+	d.dropCalls()
+}
+
+// ResetFireRecall ends Phase I. Recalled cars go back into service where
+// they stand; a car under firefighter control stays in Phase II until its
+// key is switched off.
+func (d *Dispatcher) ResetFireRecall() {
+	for _, car := range d.Elevators {
+		car.EndFireRecall()
+	}
+}
+
+// FirefighterService turns the Phase II key switch in car carID; see
+// SetFirefighterService on the cars.
+func (d *Dispatcher) FirefighterService(carID int, on bool) error {
+	car, err := d.car(carID)
+	if err != nil {
+		return err
+	}
+	return car.SetFirefighterService(on)
+}
+
+// recallFloorFor returns floor if car stops there, else the served floor
+// nearest to it.
+func recallFloorFor(car CarModel, floor int) int {
+	if car.Serves(floor) {
+		return floor
+	}
+	return slices.MinFunc(car.ServedFloors(), func(a, b int) int {
+		return cmp.Compare(abs(a-floor), abs(b-floor))
+	})
+}
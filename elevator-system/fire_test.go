@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+// runUntilFireService steps car until it holds its door in fire service and
+// returns the floors it stopped at on the way.
+func runUntilFireService(car CarModel, maxSteps int) []int {
+	var stops []int
+	for range maxSteps {
+		for _, ev := range car.StepEvents() {
+			if ev.Kind == StepStopped {
+				stops = append(stops, ev.Floor)
+			}
+		}
+		if car.CurrentState() == StateFireService {
+			break
+		}
+	}
+	return stops
+}
+
+func TestFireRecall_RunsNonStopToRecallFloor(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			rider := NewPassenger(1, 1, 9, 0)
+			car.AddPassenger(rider) // boards at once
+			waiter := NewPassenger(2, 7, 2, 0)
+			car.AddPassenger(waiter)
+			car.AddRequest(Request{Floor: 3, Direction: DirDown, Type: HallCall})
+			for car.Floor() < 5 {
+				car.Step()
+			}
+
+			if err := car.FireRecall(2); err != nil {
+				t.Fatal(err)
+			}
+			if car.HasPendingRequests() || waiter.State != PassengerEvacuated {
+				t.Errorf("expected every stop cancelled and waiter turned away, got %d stops (waiter %s)",
+					car.PendingCount(), waiter.State)
+			}
+			if car.FirePhase() != FirePhaseI || car.CurrentDirection() != DirDown {
+				t.Errorf("expected Phase I heading down, got %s %s", car.FirePhase(), car.CurrentDirection())
+			}
+
+			if stops := runUntilFireService(car, 20); len(stops) != 0 {
+				t.Errorf("expected a non-stop run, stopped at %v", stops)
+			}
+			if car.Floor() != 2 || car.CurrentState() != StateFireService {
+				t.Fatalf("expected parked at 2 in fire service, got %s at %d", car.CurrentState(), car.Floor())
+			}
+			if rider.State != PassengerEvacuated || len(car.Occupants()) != 0 {
+				t.Errorf("expected the rider let out, got %s", rider.State)
+			}
+
+			// Parked: the door stays open and nothing is accepted.
+			for range 5 {
+				if evs := car.StepEvents(); evs[0].Kind != StepFireService {
+					t.Fatalf("expected door held, got %v", evs[0].Kind)
+				}
+			}
+			if err := car.AddRequest(Request{Floor: 6, Type: CabCall}); !errors.Is(err, ErrFireService) {
+				t.Errorf("expected ErrFireService, got %v", err)
+			}
+			if car.Park(5) {
+				t.Error("expected Park refused in fire service")
+			}
+		})
+	}
+}
+
+func TestFireRecall_DoorOpenClosesFirst(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.CurrentFloor = 6
+	e.AddRequest(Request{Floor: 6, Type: CabCall}) // door opens at 6
+
+	e.FireRecall(1)
+	if evs, _ := stepBody(e.StepEvents()); evs[0].Kind != StepDoorClosed || e.State != StateMovingDown {
+		t.Errorf("expected the door to close and the car to head down, got %v %s", evs[0].Kind, e.State)
+	}
+	runUntilFireService(e, 20)
+	if e.CurrentFloor != 1 || e.State != StateFireService {
+		t.Errorf("expected parked at 1, got %s at %d", e.State, e.CurrentFloor)
+	}
+}
+
+func TestFireRecall_AlreadyAtRecallFloor(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	e.FireRecall(1)
+	if e.State != StateFireService {
+		t.Errorf("expected door held at once, got %s", e.State)
+	}
+	if err := e.FireRecall(11); !errors.Is(err, ErrFloorNotServed) {
+		t.Errorf("expected ErrFloorNotServed, got %v", err)
+	}
+}
+
+func TestFirefighterService_AnswersOnlyCabCalls(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	car := d.Elevators[0]
+	if err := d.FirefighterService(1, true); !errors.Is(err, ErrNotRecalled) {
+		t.Errorf("expected ErrNotRecalled outside fire service, got %v", err)
+	}
+	car.AddRequest(Request{Floor: 4, Type: CabCall})
+	d.StepAll() // up to 2
+	d.FireRecall(1)
+	if err := d.FirefighterService(1, true); !errors.Is(err, ErrNotRecalled) {
+		t.Errorf("expected ErrNotRecalled before parking, got %v", err)
+	}
+	runUntilFireService(car, 20)
+
+	if err := d.FirefighterService(1, true); err != nil {
+		t.Fatal(err)
+	}
+	if car.FirePhase() != FirePhaseII {
+		t.Fatalf("expected Phase II, got %s", car.FirePhase())
+	}
+	if got := d.Dispatch(Request{Floor: 5, Direction: DirDown, Type: HallCall}); got != nil {
+		t.Errorf("expected hall call refused, got car %d", got.CarID())
+	}
+	if err := car.AddRequest(Request{Floor: 7, Type: CabCall}); err != nil {
+		t.Fatal(err)
+	}
+	if stops := runUntilFireService(car, 20); !intSliceEqual(stops, []int{7}) {
+		t.Errorf("expected a stop at 7, got %v", stops)
+	}
+	// The door waits for the firefighter's next call.
+	for range 5 {
+		d.StepAll()
+	}
+	if car.Floor() != 7 || car.CurrentState() != StateFireService {
+		t.Errorf("expected door held at 7, got %s at %d", car.CurrentState(), car.Floor())
+	}
+
+	// Key off: the car returns to the recall floor under Phase I.
+	car.AddRequest(Request{Floor: 9, Type: CabCall})
+	d.FirefighterService(1, false)
+	if car.HasPendingRequests() {
+		t.Error("expected cab calls cancelled with the key off")
+	}
+	if stops := runUntilFireService(car, 20); len(stops) != 0 || car.Floor() != 1 {
+		t.Errorf("expected non-stop return to 1, stopped at %v, now at %d", stops, car.Floor())
+	}
+	if car.FirePhase() != FirePhaseI {
+		t.Errorf("expected Phase I again, got %s", car.FirePhase())
+	}
+}
+
+func TestDispatcher_FireRecall(t *testing.T) {
+	d := NewDispatcher(3, 1, 20)
+	elevatorAt(d, 1).CurrentFloor = 10
+	elevatorAt(d, 2).CurrentFloor = 18
+	elevatorAt(d, 2).SetServedFloors(1, 15, 16, 17, 18, 19, 20) // express: lobby and high floors
+	d.Dispatch(Request{Floor: 12, Direction: DirDown, Type: HallCall})
+	d.DispatchPassenger(NewPassenger(1, 6, 14, 0))
+
+	d.FireRecall(1)
+	if calls := d.OutstandingCalls(); len(calls) != 0 {
+		t.Errorf("expected hall calls dropped, got %v", calls)
+	}
+	if car := d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}); car != nil {
+		t.Errorf("expected no car during recall, got %d", car.CarID())
+	}
+	for range 30 {
+		d.StepAll()
+	}
+	for _, car := range d.Elevators {
+		if car.Floor() != 1 || car.CurrentState() != StateFireService {
+			t.Errorf("car %d: expected parked at 1, got %s at %d", car.CarID(), car.CurrentState(), car.Floor())
+		}
+	}
+
+	if err := d.FirefighterService(2, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.FirefighterService(9, true); !errors.Is(err, ErrUnknownCar) {
+		t.Errorf("expected ErrUnknownCar, got %v", err)
+	}
+	if err := d.Elevators[0].AddRequest(Request{Floor: 8, Type: CabCall}); !errors.Is(err, ErrFireService) {
+		t.Errorf("expected cab calls refused in cars without the key, got %v", err)
+	}
+
+	d.ResetFireRecall()
+	if d.Elevators[1].FirePhase() != FirePhaseII {
+		t.Error("expected the firefighter to keep car 2")
+	}
+	if car := d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}); car == nil || car.CarID() == 2 {
+		t.Errorf("expected a recalled car back in service, got %v", car)
+	}
+}
+
+func TestDispatcher_FireRecallToNearestServedFloor(t *testing.T) {
+	d := newBankedDispatcher()
+	d.FireRecall(1)
+	for range 30 {
+		d.StepAll()
+	}
+	if f := d.Elevators[1].Floor(); f != 11 {
+		t.Errorf("expected the high-rise car recalled to the sky lobby, got %d", f)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// carTrace is everything observable about a car after one operation; every
+// implementation must produce the same trace.
+type carTrace struct {
+	step               string
+	floor              int
+	state              ElevatorState
+	dir                Direction
+	cabUp, cabDown     []int
+	hallUp, hallDown   []int
+	pending            int
+	hasPending, hasVIP bool
+	parking, inService bool
+}
+
+func traceOf(car CarModel, step string) carTrace {
+	tr := carTrace{
+		step:       step,
+		floor:      car.Floor(),
+		state:      car.CurrentState(),
+		dir:        car.CurrentDirection(),
+		pending:    car.PendingCount(),
+		hasPending: car.HasPendingRequests(),
+		hasVIP:     car.HasVIPRequests(),
+		parking:    car.Parking(),
+		inService:  !car.InMaintenance(),
+	}
+	tr.cabUp, tr.cabDown = car.StopsCabSnapshot()
+	tr.hallUp, tr.hallDown = car.StopsHallSnapshot()
+	return tr
+}
+
+func (a carTrace) equal(b carTrace) bool {
+	return a.step == b.step && a.floor == b.floor && a.state == b.state && a.dir == b.dir &&
+		slices.Equal(a.cabUp, b.cabUp) && slices.Equal(a.cabDown, b.cabDown) &&
+		slices.Equal(a.hallUp, b.hallUp) && slices.Equal(a.hallDown, b.hallDown) &&
+		a.pending == b.pending && a.hasPending == b.hasPending && a.hasVIP == b.hasVIP &&
+		a.parking == b.parking && a.inService == b.inService
+}
+
+// hasStop reports whether the trace holds a stop at floor in any stop set.
+func (a carTrace) hasStop(floor int) bool {
+	for _, fs := range [][]int{a.cabUp, a.cabDown, a.hallUp, a.hallDown} {
+		if slices.Contains(fs, floor) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fuzz operations, one per input byte pair (op, floor).
+const (
+	fuzzStep = iota
+	fuzzCab
+	fuzzHallUp
+	fuzzHallDown
+	fuzzVIPCab
+	fuzzPark
+	fuzzRelease
+	fuzzMaintenance
+	fuzzCancel
+	fuzzOps
+)
+
+// FuzzCarsMatch drives every car implementation with the same random
+// sequence of requests interleaved with steps and checks that:
+//   - all cars show identical floor / state / direction / stop-set traces
+//   - no car leaves MinFloor..MaxFloor
+//   - every accepted request is eventually served: once input runs out,
+//     every car goes idle with no stops left, having opened its door at
+//     each requested floor
+//
+// The first two bytes pick the building (MinFloor -2..2, 2..64 floors, so
+// the uint64 bitmask car fits); the rest are (op, floor) pairs.
+func FuzzCarsMatch(f *testing.F) {
+	f.Add([]byte{2, 8, fuzzCab, 7, fuzzCab, 3, fuzzHallUp, 5, fuzzCab, 9})
+	f.Add([]byte{0, 19, fuzzHallDown, 15, fuzzStep, 0, fuzzStep, 0, fuzzHallUp, 2, fuzzVIPCab, 10, fuzzStep, 0, fuzzCab, 0})
+	f.Add([]byte{4, 63, fuzzCab, 63, fuzzStep, 0, fuzzCab, 0, fuzzRelease, 0, fuzzPark, 30})
+	f.Add([]byte{1, 10, fuzzPark, 9, fuzzStep, 0, fuzzHallDown, 4, fuzzMaintenance, 0, fuzzCab, 2, fuzzMaintenance, 0})
+	f.Add([]byte{2, 12, fuzzCab, 9, fuzzCab, 4, fuzzStep, 0, fuzzStep, 0, fuzzCancel, 9, fuzzStep, 0, fuzzCancel, 4})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if len(data) < 2 {
+			return
+		}
+		minFloor := int(data[0]%5) - 2
+		maxFloor := minFloor + 1 + int(data[1]%63)
+		ops := data[2:]
+
+		var cars []CarModel
+		var names []string
+		for name, newCar := range carConstructors {
+			cars = append(cars, newCar(1, minFloor, maxFloor))
+			names = append(names, name)
+		}
+
+		// Floors with a stop some car accepted and has not opened its door at since.
+		unserved := make(map[int]bool)
+		check := func(what string, steps []string) carTrace {
+			t.Helper()
+			want := traceOf(cars[0], steps[0])
+			for i, car := range cars[1:] {
+				if got := traceOf(car, steps[i+1]); !got.equal(want) {
+					t.Fatalf("after %s: %s diverged from %s:\n  %s: %+v\n  %s: %+v",
+						what, names[i+1], names[0], names[0], want, names[i+1], got)
+				}
+			}
+			if want.floor < minFloor || want.floor > maxFloor {
+				t.Fatalf("after %s: car at floor %d outside %d..%d", what, want.floor, minFloor, maxFloor)
+			}
+			if want.state == StateDoorOpen {
+				delete(unserved, want.floor)
+			}
+			return want
+		}
+		step := func() {
+			steps := make([]string, len(cars))
+			for i, car := range cars {
+				steps[i] = car.Step()
+			}
+			check("Step", steps)
+		}
+		request := func(r Request) {
+			for _, car := range cars {
+				car.AddRequest(r)
+			}
+			// A refused request (maintenance, or a cab call for the floor a
+			// moving car is leaving) is simply not latched.
+			if tr := check(r.String(), make([]string, len(cars))); tr.hasStop(r.Floor) {
+				unserved[r.Floor] = true
+			}
+		}
+
+		for i := 0; i+1 < len(ops); i += 2 {
+			floor := minFloor + int(ops[i+1])%(maxFloor-minFloor+1)
+			switch ops[i] % fuzzOps {
+			case fuzzStep:
+				step()
+			case fuzzCab:
+				request(Request{Floor: floor, Type: CabCall})
+			case fuzzHallUp:
+				request(Request{Floor: floor, Direction: DirUp, Type: HallCall})
+			case fuzzHallDown:
+				request(Request{Floor: floor, Direction: DirDown, Type: HallCall})
+			case fuzzVIPCab:
+				request(Request{Floor: floor, Type: CabCall, Priority: PriorityVIP})
+			case fuzzPark:
+				for _, car := range cars {
+					car.Park(floor)
+				}
+				check(fmt.Sprintf("Park(%d)", floor), make([]string, len(cars)))
+			case fuzzRelease:
+				for _, dir := range []Direction{DirUp, DirDown} {
+					for _, car := range cars {
+						car.ReleaseHallCall(floor, dir)
+					}
+				}
+				if tr := check(fmt.Sprintf("ReleaseHallCall(%d)", floor), make([]string, len(cars))); !tr.hasStop(floor) {
+					delete(unserved, floor)
+				}
+			case fuzzMaintenance:
+				for _, car := range cars {
+					car.SetMaintenance(!car.InMaintenance())
+				}
+				check("SetMaintenance", make([]string, len(cars)))
+			case fuzzCancel:
+				for _, r := range []Request{
+					{Floor: floor, Type: CabCall},
+					{Floor: floor, Direction: DirUp, Type: HallCall},
+					{Floor: floor, Direction: DirDown, Type: HallCall},
+				} {
+					for _, car := range cars {
+						car.CancelRequest(r)
+					}
+				}
+				if tr := check(fmt.Sprintf("CancelRequest(%d)", floor), make([]string, len(cars))); !tr.hasStop(floor) {
+					delete(unserved, floor)
+				}
+			}
+		}
+
+		// LOOK needs at most two sweeps plus a door cycle per stop.
+		floors := maxFloor - minFloor + 1
+		limit := 4*floors + 3*len(ops) + 10
+		for range limit {
+			if cars[0].CurrentState() == StateIdle && !cars[0].HasPendingRequests() {
+				break
+			}
+			step()
+		}
+		if cars[0].CurrentState() != StateIdle || cars[0].HasPendingRequests() {
+			t.Fatalf("not idle after %d steps: %+v", limit, traceOf(cars[0], ""))
+		}
+		if len(unserved) != 0 {
+			t.Fatalf("requests never served at floors %v", unserved)
+		}
+	})
+}
@@ -0,0 +1,21 @@
+package main
+
+// carConstructors builds one car of each implementation over the same floors.
+// Tests loop over it for behavior that touches how a car stores its stops;
+// the rest is covered once, on an Elevator or through a Dispatcher.
+var carConstructors = map[string]func(id, minFloor, maxFloor int) CarModel{
+	"bool":      func(id, lo, hi int) CarModel { return NewElevator(id, lo, hi) },
+	"bitmask":   func(id, lo, hi int) CarModel { return NewBitmaskElevator(id, lo, hi) },
+	"bitset":    func(id, lo, hi int) CarModel { return NewBitsetElevator(id, lo, hi) },
+	"multiword": func(id, lo, hi int) CarModel { return NewMultiwordElevator(id, lo, hi) },
+}
+
+// runCarUntilIdle drives any car until it is idle with nothing pending.
+func runCarUntilIdle(car CarModel, maxSteps int) {
+	for range maxSteps {
+		car.Step()
+		if car.CurrentState() == StateIdle && !car.HasPendingRequests() {
+			return
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// Kinematics is a car's physical timing: how fast it covers floors and how
+// long its door takes. Step still moves one floor at a time; Kinematics says
+// how much real time those steps take (see Simulator.Timed) and lets
+// CostPolicy express cost in seconds.
+type Kinematics struct {
+	FloorHeight  float64 // metres between floors
+	MaxSpeed     float64 // m/s; 0 disables timing for the car
+	Acceleration float64 // m/s²; 0: reaches MaxSpeed instantly
+	Jerk         float64 // m/s³; 0: acceleration changes instantly
+
+	DoorOpen          time.Duration // door opening
+	DoorClose         time.Duration // door closing
+	Dwell             time.Duration // door fully open with nobody boarding
+	DwellPerPassenger time.Duration // extra dwell per passenger boarding or alighting
+}
+
+// DefaultKinematics is a typical mid-rise traction car.
+func DefaultKinematics() Kinematics {
+	return Kinematics{
+		FloorHeight:       3.5,
+		MaxSpeed:          2.5,
+		Acceleration:      1.0,
+		Jerk:              1.5,
+		DoorOpen:          2 * time.Second,
+		DoorClose:         3 * time.Second,
+		Dwell:             2 * time.Second,
+		DwellPerPassenger: time.Second,
+	}
+}
+
+// TravelTime is the time to run floors floors from rest to rest.
+func (k Kinematics) TravelTime(floors int) time.Duration {
+	return k.travel(float64(floors) * k.FloorHeight)
+}
+
+// FloorTime is the time to pass one floor at full speed.
+func (k Kinematics) FloorTime() time.Duration {
+	if k.MaxSpeed <= 0 {
+		return 0
+	}
+	return seconds(k.FloorHeight / k.MaxSpeed)
+}
+
+// DwellTime is how long the door stays fully open while transfers passengers
+// board or alight.
+func (k Kinematics) DwellTime(transfers int) time.Duration {
+	return k.Dwell + time.Duration(transfers)*k.DwellPerPassenger
+}
+
+// DoorCycle is the full time a stop holds the car: open, dwell, close.
+func (k Kinematics) DoorCycle(transfers int) time.Duration {
+	return k.DoorOpen + k.DwellTime(transfers) + k.DoorClose
+}
+
+// travel is the time to cover distance metres from rest to rest.
+//
+// The speed profile is the usual S-curve: acceleration ramps up at Jerk to
+// Acceleration, holds, ramps down as the car reaches MaxSpeed, and braking
+// mirrors it. Accelerating to speed v and braking back covers v·accelTime(v)
+// metres, so a run long enough to reach MaxSpeed takes
+//
+//	distance/MaxSpeed + accelTime(MaxSpeed)
+//
+// and a shorter one peaks at the speed whose accelerate-and-brake distance
+// is exactly the run, found by bisection.
+func (k Kinematics) travel(distance float64) time.Duration {
+	if distance <= 0 || k.MaxSpeed <= 0 {
+		return 0
+	}
+	if t := k.accelTime(k.MaxSpeed); distance >= k.MaxSpeed*t {
+		return seconds(distance/k.MaxSpeed + t)
+	}
+	lo, hi := 0.0, k.MaxSpeed
+	for range 60 {
+		v := (lo + hi) / 2
+		if v*k.accelTime(v) < distance {
+			lo = v
+		} else {
+			hi = v
+		}
+	}
+	return seconds(2 * k.accelTime(hi))
+}
+
+// accelTime is the time to accelerate from rest to speed v.
+func (k Kinematics) accelTime(v float64) float64 {
+	switch {
+	case k.Acceleration <= 0:
+		return 0
+	case k.Jerk <= 0:
+		return v / k.Acceleration
+	case v*k.Jerk >= k.Acceleration*k.Acceleration:
+		// Reaches full acceleration: two jerk ramps around a constant phase.
+		return v/k.Acceleration + k.Acceleration/k.Jerk
+	default:
+		// Too slow to reach full acceleration: two jerk ramps only.
+		return 2 * math.Sqrt(v/k.Jerk)
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// motion holds a car's Kinematics. The car itself still moves a floor per
+// step; the Simulator and the timed dispatch policies read it to put seconds
+// on those steps.
+type motion struct {
+	kin Kinematics
+}
+
+func newMotion() motion {
+	return motion{kin: DefaultKinematics()}
+}
+
+// Kinematics returns the car's timing model.
+func (m *motion) Kinematics() Kinematics { return m.kin }
+
+// SetKinematics replaces the car's timing model, e.g. for an express car.
+func (m *motion) SetKinematics(k Kinematics) { m.kin = k }
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func durationNear(got, want time.Duration) bool {
+	diff := got - want
+	return diff > -time.Millisecond && diff < time.Millisecond
+}
+
+func TestKinematics_TravelTime(t *testing.T) {
+	trapezoid := Kinematics{FloorHeight: 1, MaxSpeed: 2, Acceleration: 1}
+	tests := []struct {
+		name   string
+		k      Kinematics
+		floors int
+		want   time.Duration
+	}{
+		{"zero floors", DefaultKinematics(), 0, 0},
+		{"constant speed", Kinematics{FloorHeight: 1, MaxSpeed: 2}, 10, 5 * time.Second},
+		// 10 m at 2 m/s plus 2 s to reach it.
+		{"trapezoid cruising", trapezoid, 10, 7 * time.Second},
+		// Peaks at √2 m/s: 2·√2 s.
+		{"trapezoid short", trapezoid, 2, 2828427 * time.Microsecond},
+		// 35 m at 2.5 m/s plus 2.5 + 1/1.5 s of jerk-limited acceleration.
+		{"s-curve cruising", DefaultKinematics(), 10, 17166667 * time.Microsecond},
+	}
+	for _, tt := range tests {
+		if got := tt.k.TravelTime(tt.floors); !durationNear(got, tt.want) {
+			t.Errorf("%s: TravelTime(%d) = %v, want %v", tt.name, tt.floors, got, tt.want)
+		}
+	}
+
+	k := DefaultKinematics()
+	for n := 1; n <= 40; n++ {
+		got := k.TravelTime(n)
+		if got <= k.TravelTime(n-1) || got < time.Duration(n)*k.FloorTime() {
+			t.Fatalf("TravelTime(%d) = %v: must grow and never beat full speed", n, got)
+		}
+	}
+}
+
+func TestKinematics_DwellScalesWithTransfers(t *testing.T) {
+	k := DefaultKinematics()
+	if got := k.DwellTime(0); got != k.Dwell {
+		t.Errorf("DwellTime(0) = %v, want %v", got, k.Dwell)
+	}
+	if got := k.DwellTime(4); got != k.Dwell+4*k.DwellPerPassenger {
+		t.Errorf("DwellTime(4) = %v, want %v", got, k.Dwell+4*k.DwellPerPassenger)
+	}
+	if got := k.DoorCycle(1); got != k.DoorOpen+k.DwellTime(1)+k.DoorClose {
+		t.Errorf("DoorCycle(1) = %v", got)
+	}
+}
+
+func TestKinematics_PerCar(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			if car.Kinematics() != DefaultKinematics() {
+				t.Errorf("expected default kinematics, got %+v", car.Kinematics())
+			}
+			fast := DefaultKinematics()
+			fast.MaxSpeed = 6
+			car.SetKinematics(fast)
+			if car.Kinematics().MaxSpeed != 6 {
+				t.Errorf("expected SetKinematics to stick, got %+v", car.Kinematics())
+			}
+		})
+	}
+}
+
+func TestSimulator_Timed_SinglePassenger(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	s := NewSimulator(d, time.Second)
+	s.Timed = true
+	k := d.Elevators[0].Kinematics()
+
+	p := s.AddPassenger(0, 1, 5)
+	r := s.Run()
+	if len(r.Passengers) != 1 {
+		t.Fatalf("expected 1 completed trip, got %d", len(r.Passengers))
+	}
+
+	// Door already open at 1: one boarding dwell, close, run four floors, open.
+	if p.WaitTime() != 0 {
+		t.Errorf("expected wait 0s, got %v", p.WaitTime())
+	}
+	want := k.DwellTime(1) + k.DoorClose + k.TravelTime(4) + k.DoorOpen
+	if !durationNear(p.RideTime(), want) {
+		t.Errorf("expected ride %v, got %v", want, p.RideTime())
+	}
+	// The car then dwells for the alighting rider and closes.
+	if !durationNear(r.Elapsed, want+k.DwellTime(1)+k.DoorClose) {
+		t.Errorf("expected run to end at %v, got %v", want+k.DwellTime(1)+k.DoorClose, r.Elapsed)
+	}
+}
+
+func TestSimulator_Timed_FasterCarArrivesFirst(t *testing.T) {
+	slow, fast := NewElevator(1, 1, 30), NewElevator(2, 1, 30)
+	k := DefaultKinematics()
+	k.MaxSpeed = 6
+	fast.SetKinematics(k)
+
+	d := NewDispatcherWithCars(1, 30, []CarModel{slow, fast}, WithPolicy(&RoundRobinPolicy{}))
+	s := NewSimulator(d, time.Second)
+	s.Timed = true
+	ps := s.AddPassenger(0, 30, 1) // round robin: car 1
+	pf := s.AddPassenger(0, 30, 1) // car 2
+	s.Run()
+
+	if pf.BoardTime >= ps.BoardTime {
+		t.Errorf("expected the fast car to arrive first, got fast %v slow %v", pf.BoardTime, ps.BoardTime)
+	}
+	if want := slow.Kinematics().TravelTime(29) + slow.Kinematics().DoorOpen; !durationNear(ps.BoardTime, want) {
+		t.Errorf("expected slow car to board at %v, got %v", want, ps.BoardTime)
+	}
+}
+
+func TestCostPolicy_Timed(t *testing.T) {
+	// Car 1 idles 7 floors away; car 2 is 2 floors away heading up but has
+	// three stops to make first.
+	newFleet := func(policy DispatchPolicy) *Dispatcher {
+		d := NewDispatcher(2, 1, 20, WithPolicy(policy))
+		busy := elevatorAt(d, 1)
+		busy.CurrentFloor = 6
+		for _, f := range []int{7, 9, 10} {
+			busy.AddRequest(Request{Floor: f, Type: CabCall})
+		}
+		return d
+	}
+	r := Request{Floor: 8, Direction: DirUp, Type: HallCall}
+
+	if chosen := newFleet(NewCostPolicy()).Dispatch(r); chosen.CarID() != 2 {
+		t.Errorf("floor cost: expected nearby car 2, got %d", chosen.CarID())
+	}
+	if chosen := newFleet(&CostPolicy{Timed: true}).Dispatch(r); chosen.CarID() != 1 {
+		t.Errorf("timed cost: expected idle car 1, door stops outweigh distance, got %d", chosen.CarID())
+	}
+}
@@ -1,6 +1,11 @@
 package main
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+
+	"system-design/elevator-system/metrics"
+)
 
 func main() {
 	fmt.Println("========================================")
@@ -10,6 +15,14 @@ func main() {
 	demoLevel1()
 	demoLevel2()
 	demoLevel3()
+	demoSimulation()
+	demoTraffic()
+	demoMetrics()
+	demoKinematics()
+	demoSkyLobby()
+	demoFireService()
+	demoPowerFailure()
+	demoFaults()
 }
 
 func demoLevel1() {
@@ -73,14 +86,17 @@ func demoLevel2() {
 
 func demoLevel3() {
 	fmt.Println("\n--- Level 3: Multi-Elevator Dispatch ---")
-	fmt.Println("Scenario: 3 elevators, 10 floors")
+	fmt.Println("Scenario: 3 elevators, 10 floors (mixed fleet: []bool, bitmask, bitset)")
 	fmt.Println("  E1 at floor 1, E2 at floor 5, E3 at floor 9")
 	fmt.Println()
 
-	d := NewDispatcher(3, 1, 10)
-	d.Elevators[0].CurrentFloor = 1
-	d.Elevators[1].CurrentFloor = 5
-	d.Elevators[2].CurrentFloor = 9
+	e1 := NewElevator(1, 1, 10)
+	e2 := NewBitmaskElevator(2, 1, 10)
+	e3 := NewBitsetElevator(3, 1, 10)
+	e1.CurrentFloor = 1
+	e2.CurrentFloor = 5
+	e3.CurrentFloor = 9
+	d := NewDispatcherWithCars(1, 10, []CarModel{e1, e2, e3})
 
 	// Dispatch several hall calls.
 	requests := []Request{
@@ -92,7 +108,7 @@ func demoLevel3() {
 
 	for _, r := range requests {
 		chosen := d.Dispatch(r)
-		fmt.Printf("  Dispatched %s → Elevator %d\n", r, chosen.ID)
+		fmt.Printf("  Dispatched %s → Elevator %d\n", r, chosen.CarID())
 	}
 
 	fmt.Println("\nRunning simulation:")
@@ -100,18 +116,273 @@ func demoLevel3() {
 
 	for i := 1; i <= 30; i++ {
 		msgs := d.StepAll()
-		anyActive := false
 		for _, m := range msgs {
 			fmt.Printf("  Step %2d: %s\n", i, m)
 		}
-		for _, e := range d.Elevators {
-			if e.State != StateIdle || e.HasPendingRequests() {
-				anyActive = true
-			}
-		}
-		if !anyActive {
+		if d.AllIdle() {
 			fmt.Println("\n  All elevators idle.")
 			break
 		}
 	}
 }
+
+func demoSimulation() {
+	fmt.Println("\n--- Discrete-Event Simulation ---")
+	fmt.Println("Scenario: 2 elevators, 10 floors, 1 step = 1s")
+	fmt.Println()
+
+	d := NewDispatcher(2, 1, 10)
+	s := NewSimulator(d, time.Second)
+	s.OnEvent = func(ev SimEvent) {
+		if ev.Kind == EventPassengerArrival {
+			fmt.Printf("  t=%-4v passenger %d arrives at floor %d\n", ev.At, ev.PassengerID, ev.Floor)
+		}
+	}
+
+	s.AddPassenger(0, 1, 8)
+	s.AddPassenger(3*time.Second, 6, 2)
+	s.AddPassenger(5*time.Second, 4, 9)
+	s.AddPassenger(40*time.Second, 10, 1)
+
+	r := s.Run()
+	fmt.Println()
+	for _, p := range r.Passengers {
+		fmt.Printf("  passenger %d: %d → %d  wait=%v ride=%v\n",
+			p.ID, p.Origin, p.Destination, p.WaitTime(), p.RideTime())
+	}
+	fmt.Printf("\n  avg wait=%v  avg ride=%v  events=%d  simulated=%v\n",
+		r.AvgWait, r.AvgRide, r.Events, r.Elapsed)
+}
+
+func demoTraffic() {
+	fmt.Println("\n--- Generated Traffic × Dispatch Policies ---")
+	fmt.Println("Scenario: 4 elevators, 20 floors, 1 hour at 8 passengers/min (seed 1)")
+	fmt.Println("  Average wait per traffic profile and dispatch policy")
+	fmt.Println()
+
+	profiles := []TrafficProfile{TrafficUpPeak, TrafficDownPeak, TrafficLunch, TrafficInterFloor}
+	policies := []struct {
+		name string
+		new  func() DispatchPolicy
+	}{
+		{"Cost", func() DispatchPolicy { return NewCostPolicy() }},
+		{"Nearest", func() DispatchPolicy { return NearestCarPolicy{} }},
+		{"RoundRobin", func() DispatchPolicy { return &RoundRobinPolicy{} }},
+		{"Zone", func() DispatchPolicy { return ZonePolicy{} }},
+		{"ETA", func() DispatchPolicy { return ETAPolicy{} }},
+	}
+
+	fmt.Printf("  %-12s", "")
+	for _, p := range policies {
+		fmt.Printf("%-12s", p.name)
+	}
+	fmt.Println()
+	for _, profile := range profiles {
+		arrivals := NewTrafficGenerator(profile, 1, 20, 1).Generate(time.Hour, 8)
+		fmt.Printf("  %-12s", profile)
+		for _, p := range policies {
+			s := NewSimulator(NewDispatcher(4, 1, 20, WithPolicy(p.new())), time.Second)
+			s.AddArrivals(arrivals)
+			r := s.Run()
+			fmt.Printf("%-12v", r.AvgWait.Round(100*time.Millisecond))
+		}
+		fmt.Println()
+	}
+}
+
+func demoMetrics() {
+	fmt.Println("\n--- Operational Metrics per Stop-Set Implementation ---")
+	fmt.Println("Scenario: 4 elevators, 20 floors, 1 hour of lunch traffic at 8 passengers/min (seed 1)")
+	fmt.Println()
+
+	arrivals := NewTrafficGenerator(TrafficLunch, 1, 20, 1).Generate(time.Hour, 8)
+	impls := []struct {
+		name string
+		new  func(id int) CarModel
+	}{
+		{"[]bool", func(id int) CarModel { return NewElevator(id, 1, 20) }},
+		{"bitmask", func(id int) CarModel { return NewBitmaskElevator(id, 1, 20) }},
+		{"bitset", func(id int) CarModel { return NewBitsetElevator(id, 1, 20) }},
+		{"multiword", func(id int) CarModel { return NewMultiwordElevator(id, 1, 20) }},
+	}
+
+	fmt.Printf("  %-9s %9s %9s %9s %6s %7s %6s %8s %9s\n",
+		"", "avg wait", "p95 wait", "p95 trip", "stops", "floors", "doors", "energy", "run time")
+	for _, impl := range impls {
+		cars := make([]CarModel, 4)
+		for i := range cars {
+			cars[i] = impl.new(i + 1)
+		}
+		d := NewDispatcherWithCars(1, 20, cars)
+		c := metrics.New()
+		d.Events.Subscribe(MetricsSink(c))
+
+		s := NewSimulator(d, time.Second)
+		s.AddArrivals(arrivals)
+		start := time.Now()
+		r := s.Run()
+		elapsed := time.Since(start)
+		RecordTrips(c, r.Passengers)
+
+		m := c.Report()
+		total := m.Total()
+		fmt.Printf("  %-9s %9v %9v %9v %6d %7d %6d %8.0f %9v\n", impl.name,
+			m.Wait.Mean.Round(100*time.Millisecond), m.Wait.P95, m.Journey.P95,
+			total.Stops, total.FloorsTraveled, total.DoorCycles, total.Energy,
+			elapsed.Round(100*time.Microsecond))
+	}
+}
+
+func demoKinematics() {
+	fmt.Println("\n--- Kinematics: Step Time vs Building Time ---")
+	fmt.Println("Scenario: 4 elevators, 20 floors, 1 hour of up-peak traffic at 8 passengers/min (seed 1)")
+	k := DefaultKinematics()
+	fmt.Printf("  Car: %.1f m/floor, %.1f m/s, %.1f m/s², %.1f m/s³, door %v open / %v close, dwell %v + %v per passenger\n",
+		k.FloorHeight, k.MaxSpeed, k.Acceleration, k.Jerk, k.DoorOpen, k.DoorClose, k.Dwell, k.DwellPerPassenger)
+	fmt.Printf("  One floor: %v, ten floors: %v\n", k.TravelTime(1).Round(10*time.Millisecond), k.TravelTime(10).Round(10*time.Millisecond))
+	fmt.Println()
+
+	arrivals := NewTrafficGenerator(TrafficUpPeak, 1, 20, 1).Generate(time.Hour, 8)
+	runs := []struct {
+		name  string
+		timed bool
+		cost  DispatchPolicy
+	}{
+		{"1s steps, floor cost", false, NewCostPolicy()},
+		{"timed, floor cost", true, NewCostPolicy()},
+		{"timed, seconds cost", true, &CostPolicy{Timed: true}},
+		{"timed, ETA", true, ETAPolicy{}},
+	}
+	fmt.Printf("  %-22s %9s %9s %9s\n", "", "avg wait", "max wait", "avg ride")
+	for _, run := range runs {
+		s := NewSimulator(NewDispatcher(4, 1, 20, WithPolicy(run.cost)), time.Second)
+		s.Timed = run.timed
+		s.AddArrivals(arrivals)
+		r := s.Run()
+		fmt.Printf("  %-22s %9v %9v %9v\n", run.name,
+			r.AvgWait.Round(100*time.Millisecond), r.MaxWait.Round(time.Second), r.AvgRide.Round(100*time.Millisecond))
+	}
+}
+
+func demoSkyLobby() {
+	fmt.Println("\n--- Express Zones and Sky Lobby ---")
+	fmt.Println("Scenario: 40 floors, sky lobby at 20")
+	fmt.Println("  Cars 1-2: low-rise 1–20, car 3: express shuttle 1 ↔ 20, cars 4-5: high-rise 20–40")
+	fmt.Println()
+
+	d := NewDispatcher(5, 1, 40, WithSkyLobbies(20))
+	zones := [][]int{floorRange(1, 20), floorRange(1, 20), {1, 20}, floorRange(20, 40), floorRange(20, 40)}
+	for i, floors := range zones {
+		d.Elevators[i].SetServedFloors(floors...)
+	}
+
+	s := NewSimulator(d, time.Second)
+	trips := [][2]int{{1, 35}, {5, 30}, {38, 2}, {25, 33}, {10, 12}}
+	for i, t := range trips {
+		s.AddPassenger(time.Duration(i)*2*time.Second, t[0], t[1])
+	}
+	r := s.Run()
+	for _, p := range r.Passengers {
+		t := trips[p.ID-1]
+		via := "direct"
+		if p.Origin != t[0] {
+			via = fmt.Sprintf("via %d", p.Origin)
+		}
+		fmt.Printf("  passenger %d: %2d → %2d  %-7s wait=%v journey=%v\n",
+			p.ID, t[0], t[1], via, p.WaitTime(), p.JourneyTime())
+	}
+}
+
+func demoFireService() {
+	fmt.Println("\n--- Fire Service ---")
+	fmt.Println("Scenario: 3 elevators, 12 floors, fire alarm with cars in service; recall floor 1")
+	fmt.Println()
+
+	d := NewDispatcher(3, 1, 12)
+	d.Elevators[1].(*Elevator).CurrentFloor = 6
+	d.Elevators[2].(*Elevator).CurrentFloor = 11
+	d.DispatchPassenger(NewPassenger(1, 1, 12, 0))
+	d.DispatchPassenger(NewPassenger(2, 9, 3, 0))
+	for range 3 {
+		d.StepAll()
+	}
+	fmt.Println("  Before the alarm:")
+	fmt.Print(d.Status())
+
+	fmt.Println("\n  Phase I: fire alarm, recall to floor 1")
+	d.FireRecall(1)
+	for i := 1; i <= 9; i++ {
+		for _, m := range d.StepAll() {
+			fmt.Printf("  Step %2d: %s\n", i, m)
+		}
+	}
+
+	fmt.Println("\n  Phase II: firefighter keys car 2, cab call to 8")
+	d.FirefighterService(2, true)
+	car := d.Elevators[1]
+	if err := d.Elevators[0].AddRequest(Request{Floor: 8, Type: CabCall}); err != nil {
+		fmt.Printf("  Car 1 cab call: %v\n", err)
+	}
+	car.AddRequest(Request{Floor: 8, Type: CabCall})
+	for range 9 {
+		car.Step()
+	}
+	fmt.Print(d.Status())
+}
+
+func demoPowerFailure() {
+	fmt.Println("\n--- Power Failure ---")
+	fmt.Println("Scenario: 3 elevators, 12 floors, power lost with two cars running; emergency power for one car at a time")
+	fmt.Println()
+
+	d := NewDispatcher(3, 1, 12)
+	d.Elevators[0].(*Elevator).CurrentFloor = 12
+	d.DispatchPassenger(NewPassenger(1, 12, 2, 0))
+	d.DispatchPassenger(NewPassenger(2, 1, 9, 0))
+	for range 6 {
+		d.StepAll()
+	}
+	d.PowerFailure()
+	fmt.Print(d.Status())
+	fmt.Println()
+	for i := 1; i <= 3; i++ {
+		for _, m := range d.StepAll() {
+			fmt.Printf("  Step %2d: %s\n", i, m)
+		}
+	}
+}
+
+func demoFaults() {
+	fmt.Println("\n--- Faults and Watchdog ---")
+	fmt.Println("Scenario: 2 elevators, 12 floors, car 1 stuck at its floor at tick 3; watchdog after 3 ticks without progress")
+	fmt.Println()
+
+	d := NewDispatcher(2, 1, 12,
+		WithFaults(FaultSchedule{{Tick: 3, CarID: 1, Fault: FaultStuck}}),
+		WithWatchdog(3))
+	d.Elevators[0].(*Elevator).CurrentFloor = 3
+	d.DispatchPassenger(NewPassenger(1, 3, 10, 0))
+	d.DispatchPassenger(NewPassenger(2, 7, 12, 0))
+	for i := 1; i <= 7; i++ {
+		for _, m := range d.StepAll() {
+			fmt.Printf("  Step %2d: %s\n", i, m)
+		}
+	}
+	fmt.Print(d.Status())
+
+	fmt.Println("\n  Car 1 repaired")
+	d.Repair(1)
+	for range 10 {
+		d.StepAll()
+	}
+	fmt.Print(d.Status())
+}
+
+// floorRange returns the floors lo..hi.
+func floorRange(lo, hi int) []int {
+	floors := make([]int, 0, hi-lo+1)
+	for f := lo; f <= hi; f++ {
+		floors = append(floors, f)
+	}
+	return floors
+}
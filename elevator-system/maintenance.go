@@ -0,0 +1,63 @@
+package main
+
+import "errors"
+
+var ErrInMaintenance = errors.New("car in maintenance")
+
+// SetMaintenance takes a car out of service or puts it back.
+//
+// A car entering maintenance finishes the cab stops of its riders but accepts
+// no new requests, and Dispatch skips it. Its hall calls, with the passengers
+// waiting for them, are handed to the cars still in service. If no other car
+// is in service, the calls stay with the car so nobody is stranded.
+func (d *Dispatcher) SetMaintenance(carID int, on bool) error {
+	car, err := d.car(carID)
+	if err != nil {
+		return err
+	}
+	car.SetMaintenance(on)
+	if !on || len(d.inService()) == 0 {
+		return nil
+	}
+	d.handOver(car)
+	return nil
+}
+
+// handOver moves car's hall calls, with the passengers waiting for them, to
+// the cars in service; a call no other car can take stays where it is.
+func (d *Dispatcher) handOver(car CarModel) {
+	up, down := car.StopsHallSnapshot()
+	for _, f := range up {
+		d.redispatch(car, d.callRequest(car, f, DirUp))
+	}
+	for _, f := range down {
+		d.redispatch(car, d.callRequest(car, f, DirDown))
+	}
+}
+
+// redispatch moves hall call r from car from to another car, together with
+// the passengers waiting for it; a call nobody was tracked for moves as a
+// plain request. Returns false, leaving the call in place, if no other car
+// can take it.
+func (d *Dispatcher) redispatch(from CarModel, r Request) bool {
+	var dests []int
+	for _, p := range from.Waiting() {
+		if p.Origin == r.Floor && p.Direction() == r.Direction {
+			dests = append(dests, p.Destination)
+		}
+	}
+	to := d.selectCarExcept(r, from, dests...)
+	if to == nil {
+		return false
+	}
+	waiting := from.ReleaseHallCall(r.Floor, r.Direction)
+	delete(d.calls, hallCallKey{from, r.Floor, r.Direction})
+	if len(waiting) == 0 {
+		to.AddRequest(r)
+	}
+	for _, p := range waiting {
+		to.AddPassenger(p)
+	}
+	d.register(to, r)
+	return true
+}
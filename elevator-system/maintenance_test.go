@@ -0,0 +1,152 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestMaintenance_FinishesStopsRefusesNew(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 6, Type: CabCall})
+
+			car.SetMaintenance(true)
+			car.AddRequest(Request{Floor: 3, Direction: DirUp, Type: HallCall})
+			car.AddPassenger(NewPassenger(1, 8, 2, 0))
+
+			if got := car.PendingCount(); got != 1 {
+				t.Fatalf("expected only the existing stop pending, got %d", got)
+			}
+			runCarUntilIdle(car, 50)
+			if car.Floor() != 6 || car.HasPendingRequests() {
+				t.Errorf("expected car to finish at 6 and stay idle, got floor %d pending %d",
+					car.Floor(), car.PendingCount())
+			}
+
+			car.SetMaintenance(false)
+			car.AddRequest(Request{Floor: 3, Direction: DirUp, Type: HallCall})
+			if !car.HasPendingRequests() {
+				t.Error("expected requests accepted again after maintenance")
+			}
+		})
+	}
+}
+
+func TestMaintenance_BoardedRidersStillDelivered(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			p := NewPassenger(1, 4, 9, 0)
+			car.AddPassenger(p)
+
+			// Pulled from service with the call still assigned: the car serves
+			// it and the rider's cab call must not be refused.
+			car.SetMaintenance(true)
+			runCarUntilIdle(car, 50)
+
+			if p.State != PassengerArrived || car.Floor() != 9 {
+				t.Errorf("expected rider delivered to 9, got %s at floor %d", p.State, car.Floor())
+			}
+		})
+	}
+}
+
+func TestReleaseHallCall_CarDoesNotRunOn(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 9, Direction: DirDown, Type: HallCall})
+			car.Step()
+			car.Step()
+
+			car.ReleaseHallCall(9, DirDown)
+			for range 20 {
+				car.Step()
+			}
+
+			if car.CurrentState() != StateIdle || car.Floor() != 3 {
+				t.Errorf("expected idle at floor 3, got %s at floor %d", car.CurrentState(), car.Floor())
+			}
+		})
+	}
+}
+
+func TestDispatcher_SkipsMaintenanceCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 0).CurrentFloor = 5
+	elevatorAt(d, 1).CurrentFloor = 10
+
+	if err := d.SetMaintenance(1, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	chosen := d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall})
+	if chosen.CarID() != 2 {
+		t.Errorf("expected car 2 while car 1 is in maintenance, got %d", chosen.CarID())
+	}
+}
+
+func TestDispatcher_MaintenanceRedispatchesHallCalls(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 1).CurrentFloor = 10
+
+	p := NewPassenger(1, 4, 1, 0)
+	if d.DispatchPassenger(p).CarID() != 1 {
+		t.Fatal("expected car 1 assigned first")
+	}
+	d.Dispatch(Request{Floor: 6, Direction: DirDown, Type: HallCall}) // plain call, no passenger
+	elevatorAt(d, 0).AddRequest(Request{Floor: 2, Type: CabCall})     // a rider's stop
+
+	if err := d.SetMaintenance(1, true); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	hallUp, hallDown := d.Elevators[0].StopsHallSnapshot()
+	if len(hallUp)+len(hallDown) != 0 {
+		t.Errorf("expected car 1 to hand over its hall calls, still has up=%v down=%v", hallUp, hallDown)
+	}
+	if w := d.Elevators[1].Waiting(); len(w) != 1 || w[0] != p {
+		t.Fatalf("expected passenger re-dispatched to car 2, got %v", w)
+	}
+	if _, down := d.Elevators[1].StopsHallSnapshot(); !intSliceEqual(down, []int{4, 6}) {
+		t.Errorf("expected car 2 to hold hall calls at 4 and 6, got %v", down)
+	}
+
+	for range 100 {
+		d.StepAll()
+		if d.AllIdle() {
+			break
+		}
+	}
+	if p.State != PassengerArrived {
+		t.Errorf("expected passenger delivered by car 2, got %s", p.State)
+	}
+	if d.Elevators[0].Floor() != 2 {
+		t.Errorf("expected car 1 to finish its cab stop at 2, got floor %d", d.Elevators[0].Floor())
+	}
+}
+
+func TestDispatcher_MaintenanceLastCarKeepsCalls(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	p := NewPassenger(1, 5, 8, 0)
+	d.DispatchPassenger(p)
+
+	d.SetMaintenance(1, true)
+
+	if d.Dispatch(Request{Floor: 3, Direction: DirUp, Type: HallCall}) != nil {
+		t.Error("expected no car available for new calls")
+	}
+	for range 50 {
+		d.StepAll()
+	}
+	if p.State != PassengerArrived {
+		t.Errorf("expected the only car to keep serving its passenger, got %s", p.State)
+	}
+}
+
+func TestDispatcher_MaintenanceUnknownCar(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	if err := d.SetMaintenance(7, true); !errors.Is(err, ErrUnknownCar) {
+		t.Errorf("expected ErrUnknownCar, got %v", err)
+	}
+}
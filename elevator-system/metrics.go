@@ -0,0 +1,37 @@
+package main
+
+import "system-design/elevator-system/metrics"
+
+// metricsKinds maps step events onto the activity the metrics package counts.
+var metricsKinds = map[StepKind]metrics.Kind{
+	StepMoved:      metrics.Move,
+	StepStopped:    metrics.Stop,
+	StepDoorOpened: metrics.DoorOpen,
+	StepDoorClosed: metrics.DoorClose,
+	StepParked:     metrics.Rest,
+	StepIdle:       metrics.Rest,
+}
+
+// MetricsSink returns an EventBus subscriber that feeds c. It works the same
+// for every car implementation, since all of them publish StepEvents:
+//
+//	c := metrics.New()
+//	d.Events.Subscribe(MetricsSink(c))
+func MetricsSink(c *metrics.Collector) func(StepEvent) {
+	return func(ev StepEvent) {
+		if kind, ok := metricsKinds[ev.Kind]; ok {
+			c.Observe(metrics.Event{CarID: ev.CarID, Kind: kind, Floor: ev.Floor})
+		}
+	}
+}
+
+// RecordTrips adds the journeys of passengers who have arrived to c,
+// e.g. SimReport.Passengers.
+func RecordTrips(c *metrics.Collector, passengers []*Passenger) {
+	for _, p := range passengers {
+		if p.State != PassengerArrived {
+			continue
+		}
+		c.RecordTrip(metrics.Trip{Arrival: p.ArrivalTime, Board: p.BoardTime, Alight: p.AlightTime})
+	}
+}
@@ -0,0 +1,217 @@
+// Package metrics collects operational numbers from elevator runs: passenger
+// wait, ride and journey times, and per-car stops, floors traveled, door
+// cycles and an energy proxy.
+//
+// The package knows nothing about a particular car implementation. Callers
+// translate their own activity into Event and Trip values; the elevator
+// demo does so from its step events, so every car type is measured the same
+// way.
+package metrics
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Kind is the type of car activity an Event reports.
+type Kind int
+
+const (
+	Move      Kind = iota // Car reached Floor
+	Stop                  // Car stopped at Floor to serve it
+	DoorOpen              // Door opened
+	DoorClose             // Door closed
+	Rest                  // Car came to rest without serving a floor (idle, parked)
+)
+
+// Event is one piece of car activity.
+type Event struct {
+	CarID int
+	Kind  Kind
+	Floor int
+}
+
+// Trip is one completed passenger journey, as times since the run started.
+type Trip struct {
+	Arrival time.Duration // pressed the hall button
+	Board   time.Duration // stepped into the car
+	Alight  time.Duration // stepped out at the destination
+}
+
+// Default energy weights: a start costs about as much as three floors of
+// cruising, since accelerating the car dominates.
+const (
+	DefaultEnergyPerFloor = 1.0
+	DefaultEnergyPerStart = 3.0
+)
+
+// Collector accumulates events and trips. It is safe for concurrent use.
+type Collector struct {
+	// Energy proxy weights (unitless): cost per floor traveled and per start
+	// from rest.
+	EnergyPerFloor float64
+	EnergyPerStart float64
+
+	mu    sync.Mutex
+	cars  map[int]*carState
+	trips []Trip
+}
+
+type carState struct {
+	CarReport
+	floor  int
+	known  bool // floor is valid
+	moving bool
+}
+
+// New creates a collector with the default energy weights.
+func New() *Collector {
+	return &Collector{
+		EnergyPerFloor: DefaultEnergyPerFloor,
+		EnergyPerStart: DefaultEnergyPerStart,
+		cars:           make(map[int]*carState),
+	}
+}
+
+// Observe records one car event.
+func (c *Collector) Observe(ev Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	car, ok := c.cars[ev.CarID]
+	if !ok {
+		car = &carState{CarReport: CarReport{CarID: ev.CarID}}
+		c.cars[ev.CarID] = car
+	}
+
+	switch ev.Kind {
+	case Move:
+		if !car.moving {
+			car.Starts++
+		}
+		if car.known {
+			car.FloorsTraveled += abs(ev.Floor - car.floor)
+		} else {
+			car.FloorsTraveled++
+		}
+		car.moving = true
+	case Stop:
+		car.Stops++
+		car.moving = false
+	case DoorOpen:
+		car.DoorCycles++
+		car.moving = false
+	case Rest:
+		car.moving = false
+	}
+	car.floor, car.known = ev.Floor, true
+}
+
+// RecordTrip records one completed passenger journey.
+func (c *Collector) RecordTrip(t Trip) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.trips = append(c.trips, t)
+}
+
+// Summary describes a distribution of durations.
+type Summary struct {
+	Count int
+	Mean  time.Duration
+	P50   time.Duration
+	P90   time.Duration
+	P95   time.Duration
+	P99   time.Duration
+	Max   time.Duration
+}
+
+// CarReport holds the counters for one car.
+type CarReport struct {
+	CarID          int
+	Stops          int
+	FloorsTraveled int
+	DoorCycles     int
+	Starts         int     // moves begun from rest
+	Energy         float64 // EnergyPerFloor*FloorsTraveled + EnergyPerStart*Starts
+}
+
+// Report is a snapshot of everything collected so far.
+type Report struct {
+	Wait    Summary // Arrival → Board
+	Ride    Summary // Board → Alight
+	Journey Summary // Arrival → Alight
+	Cars    []CarReport
+}
+
+// Total sums the per-car counters.
+func (r Report) Total() CarReport {
+	var t CarReport
+	for _, c := range r.Cars {
+		t.Stops += c.Stops
+		t.FloorsTraveled += c.FloorsTraveled
+		t.DoorCycles += c.DoorCycles
+		t.Starts += c.Starts
+		t.Energy += c.Energy
+	}
+	return t
+}
+
+// Report computes the summaries and per-car counters, cars ordered by ID.
+func (c *Collector) Report() Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	wait := make([]time.Duration, len(c.trips))
+	ride := make([]time.Duration, len(c.trips))
+	journey := make([]time.Duration, len(c.trips))
+	for i, t := range c.trips {
+		wait[i] = t.Board - t.Arrival
+		ride[i] = t.Alight - t.Board
+		journey[i] = t.Alight - t.Arrival
+	}
+
+	r := Report{Wait: Summarize(wait), Ride: Summarize(ride), Journey: Summarize(journey)}
+	for _, car := range c.cars {
+		cr := car.CarReport
+		cr.Energy = c.EnergyPerFloor*float64(cr.FloorsTraveled) + c.EnergyPerStart*float64(cr.Starts)
+		r.Cars = append(r.Cars, cr)
+	}
+	slices.SortFunc(r.Cars, func(a, b CarReport) int { return cmp.Compare(a.CarID, b.CarID) })
+	return r
+}
+
+// Summarize computes the mean, nearest-rank percentiles and maximum of ds.
+// ds is not modified.
+func Summarize(ds []time.Duration) Summary {
+	s := Summary{Count: len(ds)}
+	if len(ds) == 0 {
+		return s
+	}
+	sorted := slices.Clone(ds)
+	slices.Sort(sorted)
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+	}
+	s.Mean = total / time.Duration(len(sorted))
+	s.P50 = percentile(sorted, 50)
+	s.P90 = percentile(sorted, 90)
+	s.P95 = percentile(sorted, 95)
+	s.P99 = percentile(sorted, 99)
+	s.Max = sorted[len(sorted)-1]
+	return s
+}
+
+// percentile returns the nearest-rank p-th percentile of sorted.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100 // ceil(p/100 * n)
+	return sorted[max(rank, 1)-1]
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	var ds []time.Duration
+	for i := 100; i >= 1; i-- { // unsorted on purpose
+		ds = append(ds, time.Duration(i)*time.Second)
+	}
+	s := Summarize(ds)
+
+	want := Summary{
+		Count: 100,
+		Mean:  50500 * time.Millisecond,
+		P50:   50 * time.Second,
+		P90:   90 * time.Second,
+		P95:   95 * time.Second,
+		P99:   99 * time.Second,
+		Max:   100 * time.Second,
+	}
+	if s != want {
+		t.Errorf("Summarize = %+v, want %+v", s, want)
+	}
+	if ds[0] != 100*time.Second {
+		t.Error("Summarize must not reorder its input")
+	}
+}
+
+func TestSummarize_SmallAndEmpty(t *testing.T) {
+	if s := Summarize(nil); s != (Summary{}) {
+		t.Errorf("expected zero summary, got %+v", s)
+	}
+	s := Summarize([]time.Duration{3 * time.Second})
+	if s.P50 != 3*time.Second || s.P99 != 3*time.Second || s.Max != 3*time.Second {
+		t.Errorf("single sample: got %+v", s)
+	}
+}
+
+func TestCollector_CarCounters(t *testing.T) {
+	c := New()
+	// Car 1: 1 → 4 with one stop, then back down to 2.
+	for _, ev := range []Event{
+		{1, Move, 2}, {1, Move, 3}, {1, Move, 4}, {1, Stop, 4}, {1, DoorOpen, 4}, {1, DoorClose, 4},
+		{1, Move, 3}, {1, Move, 2}, {1, Stop, 2}, {1, DoorOpen, 2}, {1, DoorClose, 2},
+		{1, Rest, 2},
+	} {
+		c.Observe(ev)
+	}
+	// Car 2: never moves.
+	c.Observe(Event{2, Rest, 1})
+
+	r := c.Report()
+	if len(r.Cars) != 2 || r.Cars[0].CarID != 1 || r.Cars[1].CarID != 2 {
+		t.Fatalf("expected cars 1 and 2 in order, got %+v", r.Cars)
+	}
+	want := CarReport{CarID: 1, Stops: 2, FloorsTraveled: 5, DoorCycles: 2, Starts: 2, Energy: 5 + 2*3}
+	if r.Cars[0] != want {
+		t.Errorf("car 1 = %+v, want %+v", r.Cars[0], want)
+	}
+	if r.Cars[1] != (CarReport{CarID: 2}) {
+		t.Errorf("car 2 = %+v, want zero counters", r.Cars[1])
+	}
+	if total := r.Total(); total.Stops != 2 || total.Energy != 11 {
+		t.Errorf("Total = %+v", total)
+	}
+}
+
+func TestCollector_Trips(t *testing.T) {
+	c := New()
+	c.RecordTrip(Trip{Arrival: 0, Board: 4 * time.Second, Alight: 10 * time.Second})
+	c.RecordTrip(Trip{Arrival: 2 * time.Second, Board: 4 * time.Second, Alight: 6 * time.Second})
+
+	r := c.Report()
+	if r.Wait.Count != 2 || r.Wait.Mean != 3*time.Second || r.Wait.Max != 4*time.Second {
+		t.Errorf("wait = %+v", r.Wait)
+	}
+	if r.Ride.Mean != 4*time.Second || r.Journey.Max != 10*time.Second {
+		t.Errorf("ride = %+v, journey = %+v", r.Ride, r.Journey)
+	}
+}
+
+func TestCollector_EnergyWeights(t *testing.T) {
+	c := New()
+	c.EnergyPerFloor, c.EnergyPerStart = 2, 0
+	c.Observe(Event{1, Move, 2})
+	c.Observe(Event{1, Move, 3})
+	if e := c.Report().Cars[0].Energy; e != 4 {
+		t.Errorf("energy = %v, want 4", e)
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"system-design/elevator-system/metrics"
+)
+
+func TestMetrics_SameForEveryCarImplementation(t *testing.T) {
+	arrivals := NewTrafficGenerator(TrafficLunch, 1, 20, 7).Generate(30*time.Minute, 6)
+
+	var reports []metrics.Report
+	for _, name := range []string{"bool", "bitmask", "bitset"} {
+		cars := make([]CarModel, 3)
+		for i := range cars {
+			cars[i] = carConstructors[name](i+1, 1, 20)
+		}
+		d := NewDispatcherWithCars(1, 20, cars)
+		c := metrics.New()
+		d.Events.Subscribe(MetricsSink(c))
+
+		s := NewSimulator(d, time.Second)
+		s.AddArrivals(arrivals)
+		r := s.Run()
+		RecordTrips(c, r.Passengers)
+
+		m := c.Report()
+		if m.Wait.Count != len(r.Passengers) || m.Wait.Mean != r.AvgWait || m.Wait.Max != r.MaxWait {
+			t.Errorf("%s: metrics wait %+v disagrees with simulator avg=%v max=%v",
+				name, m.Wait, r.AvgWait, r.MaxWait)
+		}
+		if total := m.Total(); total.Stops == 0 || total.FloorsTraveled == 0 || total.DoorCycles < total.Stops {
+			t.Errorf("%s: implausible totals %+v", name, total)
+		}
+		reports = append(reports, m)
+	}
+
+	for i := 1; i < len(reports); i++ {
+		for j, car := range reports[i].Cars {
+			if car != reports[0].Cars[j] {
+				t.Errorf("car %d differs between implementations: %+v vs %+v", car.CarID, reports[0].Cars[j], car)
+			}
+		}
+	}
+}
+
+func TestMetricsSink_StepAll(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	c := metrics.New()
+	d.Events.Subscribe(MetricsSink(c))
+
+	d.Dispatch(Request{Floor: 4, Direction: DirDown, Type: HallCall})
+	for range 10 {
+		d.StepAll()
+	}
+
+	car := c.Report().Cars[0]
+	if car.FloorsTraveled != 3 || car.Stops != 1 || car.DoorCycles != 1 || car.Starts != 1 {
+		t.Errorf("unexpected counters %+v", car)
+	}
+}
+
+func TestMetricsSink_DoorOpenedAtOwnFloor(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	c := metrics.New()
+	d.Events.Subscribe(MetricsSink(c))
+
+	d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+	for range 5 {
+		d.StepAll()
+	}
+
+	car := c.Report().Cars[0]
+	if car.DoorCycles != 1 || car.Stops != 0 || car.FloorsTraveled != 0 {
+		t.Errorf("expected one door cycle without travel, got %+v", car)
+	}
+}
@@ -30,6 +30,13 @@ const (
 	StateMovingUp
 	StateMovingDown
 	StateDoorOpen
+	StateOverloaded    // Door held open, alarm sounding: too heavy or too full to leave
+	StateFireService   // Door held open in fire service: parked by recall, or awaiting the firefighter's next cab call
+	StateStranded      // Stopped by a power failure, waiting for emergency power
+	StateLowering      // On emergency power, running to the nearest floor
+	StateEmergencyStop // Door held open at a floor after a seismic trigger or power failure, out of service
+	StateDoorHold      // Door held open by the door-open-hold button, up to DoorConfig.MaxHoldSteps
+	StateNudging       // Door closing slowly, buzzer sounding, after repeated obstruction reopens
 )
 
 func (s ElevatorState) String() string {
@@ -40,11 +47,36 @@ func (s ElevatorState) String() string {
 		return "MovingDown"
 	case StateDoorOpen:
 		return "DoorOpen"
+	case StateOverloaded:
+		return "Overloaded"
+	case StateFireService:
+		return "FireService"
+	case StateStranded:
+		return "Stranded"
+	case StateLowering:
+		return "Lowering"
+	case StateEmergencyStop:
+		return "EmergencyStop"
+	case StateDoorHold:
+		return "DoorHold"
+	case StateNudging:
+		return "Nudging"
 	default:
 		return "Idle"
 	}
 }
 
+// doorOpen reports whether the car stands at a floor with its door open.
+func (s ElevatorState) doorOpen() bool {
+	return s.loading() || s == StateOverloaded || s == StateFireService || s == StateEmergencyStop
+}
+
+// loading reports whether the door is open for passengers: dwelling, held
+// by the hold button or nudging closed. A call at the floor reopens it.
+func (s ElevatorState) loading() bool {
+	return s == StateDoorOpen || s == StateDoorHold || s == StateNudging
+}
+
 // RequestType distinguishes between hall calls and cab calls.
 type RequestType int
 
@@ -62,14 +94,115 @@ func (t RequestType) String() string {
 
 // Request represents an elevator request.
 type Request struct {
-	Floor     int
-	Direction Direction   // Only meaningful for HallCall
+	Floor int
+	// HallCall: the button pressed. CabCall: optional trip direction, set when
+	// the stop is registered before boarding (destination dispatch); DirIdle
+	// places the stop by its position relative to the car.
+	Direction Direction
 	Type      RequestType
+	// Priority above PriorityNormal is served ahead of the LOOK order.
+	Priority int
 }
 
 func (r Request) String() string {
+	vip := ""
+	if r.Priority > PriorityNormal {
+		vip = ", VIP"
+	}
 	if r.Type == HallCall {
-		return fmt.Sprintf("HallCall(floor=%d, dir=%s)", r.Floor, r.Direction)
+		return fmt.Sprintf("HallCall(floor=%d, dir=%s%s)", r.Floor, r.Direction, vip)
+	}
+	return fmt.Sprintf("CabCall(floor=%d%s)", r.Floor, vip)
+}
+
+// cabDirection returns which cab stop set a cab call belongs to for a car at
+// floor current: the explicit trip direction if given, else up or down by
+// relative position (DirIdle when already there).
+func (r Request) cabDirection(current int) Direction {
+	switch {
+	case r.Direction != DirIdle:
+		return r.Direction
+	case r.Floor > current:
+		return DirUp
+	case r.Floor < current:
+		return DirDown
+	default:
+		return DirIdle
 	}
-	return fmt.Sprintf("CabCall(floor=%d)", r.Floor)
 }
+
+// CarModel is the surface shared by every elevator car implementation,
+// regardless of how it stores its stop sets ([]bool, uint64 bitmask, bitset,
+// []uint64 multiword).
+// Dispatcher only talks to cars through this interface, so a fleet can mix
+// implementations.
+type CarModel interface {
+	CarID() int
+	Floor() int
+	CurrentState() ElevatorState
+	CurrentDirection() Direction
+
+	AddRequest(r Request) error
+	AddPassenger(p *Passenger)
+	Step() string
+	StepEvents() []StepEvent
+	HasPendingRequests() bool
+	HasVIPRequests() bool
+	PendingCount() int
+	StopsCabSnapshot() (up []int, down []int)
+	StopsHallSnapshot() (up []int, down []int)
+	WeightSensor() bool
+	NearFull() bool
+	Capacity() Capacity
+	SetCapacity(c Capacity)
+	Occupants() []*Passenger
+	Waiting() []*Passenger
+
+	SetMaintenance(on bool)
+	InMaintenance() bool
+	ReleaseHallCall(floor int, dir Direction) []*Passenger
+
+	Park(floor int) bool
+	Parking() bool
+
+	Kinematics() Kinematics
+	SetKinematics(k Kinematics)
+
+	Serves(floor int) bool
+	ServedFloors() []int
+	SetServedFloors(floors ...int) error
+
+	FireRecall(floor int) error
+	EndFireRecall()
+	SetFirefighterService(on bool) error
+	FirePhase() FirePhase
+
+	SeismicStop()
+	PowerFailure()
+	LowerToFloor() bool
+	ResetEmergency()
+	Emergency() EmergencyMode
+
+	InjectFault(f Fault)
+	ClearFault()
+	Fault() Fault
+
+	HoldDoor() bool
+	CloseDoor() bool
+	SetObstructed(on bool)
+	Obstructed() bool
+	DoorConfig() DoorConfig
+	SetDoorConfig(c DoorConfig)
+
+	CancelRequest(r Request) bool
+	PressCabButton(floor int) error
+	SetAntiNuisance(on bool)
+	AntiNuisance() bool
+}
+
+var (
+	_ CarModel = (*Elevator)(nil)
+	_ CarModel = (*BitmaskElevator)(nil)
+	_ CarModel = (*BitsetElevator)(nil)
+	_ CarModel = (*MultiwordElevator)(nil)
+)
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// ParkingPolicy decides where idle cars wait for the next call.
+type ParkingPolicy interface {
+	// HomeFloors returns up to n floors for idle cars to park at, most
+	// important first. at is the time of day.
+	HomeFloors(d *Dispatcher, at time.Duration, n int) []int
+}
+
+// callObserver is implemented by parking policies that learn from the hall
+// calls the dispatcher sees.
+type callObserver interface {
+	ObserveCall(r Request)
+}
+
+// ParkMode is a layout for idle cars.
+type ParkMode int
+
+const (
+	ParkStay   ParkMode = iota // Leave idle cars where they stopped
+	ParkLobby                  // Return idle cars to the lobby (MinFloor)
+	ParkSpread                 // Spread idle cars evenly over the building
+	ParkUpper                  // Spread idle cars over the upper half
+)
+
+func (m ParkMode) String() string {
+	switch m {
+	case ParkLobby:
+		return "Lobby"
+	case ParkSpread:
+		return "Spread"
+	case ParkUpper:
+		return "Upper"
+	default:
+		return "Stay"
+	}
+}
+
+// ParkingRule applies Mode from From up to (not including) To, both as time of day.
+type ParkingRule struct {
+	From time.Duration
+	To   time.Duration
+	Mode ParkMode
+}
+
+// ScheduleParking picks a layout by time of day. The first matching rule
+// wins; outside every rule Default applies.
+type ScheduleParking struct {
+	Rules   []ParkingRule
+	Default ParkMode
+}
+
+// NewPeakSchedule returns the usual office schedule: lobby during the morning
+// up-peak, upper floors during the evening down-peak, spread otherwise.
+func NewPeakSchedule() *ScheduleParking {
+	return &ScheduleParking{
+		Rules: []ParkingRule{
+			{From: 7 * time.Hour, To: 10 * time.Hour, Mode: ParkLobby},
+			{From: 16 * time.Hour, To: 19 * time.Hour, Mode: ParkUpper},
+		},
+		Default: ParkSpread,
+	}
+}
+
+// Mode returns the layout in effect at time of day at.
+func (s *ScheduleParking) Mode(at time.Duration) ParkMode {
+	at %= 24 * time.Hour
+	for _, r := range s.Rules {
+		if at >= r.From && at < r.To {
+			return r.Mode
+		}
+	}
+	return s.Default
+}
+
+func (s *ScheduleParking) HomeFloors(d *Dispatcher, at time.Duration, n int) []int {
+	switch s.Mode(at) {
+	case ParkLobby:
+		floors := make([]int, n)
+		for i := range floors {
+			floors[i] = d.MinFloor
+		}
+		return floors
+	case ParkSpread:
+		return spreadFloors(d.MinFloor, d.MaxFloor, n)
+	case ParkUpper:
+		return spreadFloors(d.MinFloor+(d.MaxFloor-d.MinFloor+1)/2, d.MaxFloor, n)
+	default:
+		return nil
+	}
+}
+
+// spreadFloors returns n floors evenly spaced over [lo, hi], each in the
+// middle of its band.
+func spreadFloors(lo, hi, n int) []int {
+	floors := make([]int, n)
+	span := hi - lo + 1
+	for i := range floors {
+		floors[i] = lo + (2*i+1)*span/(2*n)
+	}
+	return floors
+}
+
+// DemandParking parks idle cars at the floors with the most recent hall
+// calls. It remembers the origins of the last Window calls; with no history
+// idle cars stay where they are.
+type DemandParking struct {
+	Window int
+
+	recent []int
+}
+
+// NewDemandParking creates a demand-driven policy over the last window calls.
+func NewDemandParking(window int) *DemandParking {
+	return &DemandParking{Window: window}
+}
+
+func (p *DemandParking) ObserveCall(r Request) {
+	p.recent = append(p.recent, r.Floor)
+	if len(p.recent) > p.Window {
+		p.recent = slices.Delete(p.recent, 0, len(p.recent)-p.Window)
+	}
+}
+
+// HomeFloors returns the busiest floors in the window, ties to the lower floor.
+func (p *DemandParking) HomeFloors(_ *Dispatcher, _ time.Duration, n int) []int {
+	counts := make(map[int]int)
+	for _, f := range p.recent {
+		counts[f]++
+	}
+	floors := make([]int, 0, len(counts))
+	for f := range counts {
+		floors = append(floors, f)
+	}
+	slices.SortFunc(floors, func(a, b int) int {
+		if c := cmp.Compare(counts[b], counts[a]); c != 0 {
+			return c
+		}
+		return cmp.Compare(a, b)
+	})
+	return floors[:min(n, len(floors))]
+}
+
+// ParkIdle sends idle cars to the parking policy's home floors for time of
+// day at. Each home, most important first, goes to the nearest idle car not
+// yet given one that serves it; a car already at its home stays put. Parking moves never
+// block dispatch: a parking car drops its move on the first real request.
+// Returns the cars that started (or changed) a parking move.
+func (d *Dispatcher) ParkIdle(at time.Duration) []CarModel {
+	if d.Parking == nil {
+		return nil
+	}
+	var idle []CarModel
+	for _, e := range d.inService() {
+		if !e.HasPendingRequests() && (e.CurrentState() == StateIdle || e.Parking()) {
+			idle = append(idle, e)
+		}
+	}
+
+	var moved []CarModel
+	for _, home := range d.Parking.HomeFloors(d, at, len(idle)) {
+		if len(idle) == 0 {
+			break
+		}
+		best := -1
+		for i, e := range idle {
+			if e.Serves(home) && (best < 0 || abs(e.Floor()-home) < abs(idle[best].Floor()-home)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		car := idle[best]
+		idle = slices.Delete(idle, best, best+1)
+		if car.Floor() != home && car.Park(home) {
+			moved = append(moved, car)
+		}
+	}
+	return moved
+}
+
+// observe feeds a hall call to a demand-driven parking policy.
+func (d *Dispatcher) observe(r Request) {
+	if o, ok := d.Parking.(callObserver); ok && r.Type == HallCall {
+		o.ObserveCall(r)
+	}
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPark_MovesWithoutOpeningDoor(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			if !car.Park(6) || !car.Parking() {
+				t.Fatal("expected idle car to start parking")
+			}
+			if car.HasPendingRequests() {
+				t.Error("parking must not register a stop")
+			}
+
+			stops := runCarStops(car, 20)
+			if len(stops) != 0 {
+				t.Errorf("expected no door openings, got %v", stops)
+			}
+			if car.Floor() != 6 || car.CurrentState() != StateIdle || car.Parking() {
+				t.Errorf("expected parked idle at 6, got %s at %d (parking=%v)",
+					car.CurrentState(), car.Floor(), car.Parking())
+			}
+		})
+	}
+}
+
+func TestPark_RefusedWhenBusy(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 4, Type: CabCall})
+			if car.Park(8) {
+				t.Error("expected busy car to refuse parking")
+			}
+			car.SetMaintenance(true)
+			runCarUntilIdle(car, 20)
+			if car.Park(8) {
+				t.Error("expected car in maintenance to refuse parking")
+			}
+		})
+	}
+}
+
+func TestPark_RealCallCancelsMove(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.Park(10)
+			car.Step()
+			car.Step()
+
+			// Parking toward 10 from floor 3; a call below wins immediately.
+			car.AddRequest(Request{Floor: 2, Direction: DirUp, Type: HallCall})
+			if car.Parking() || car.CurrentState() != StateMovingDown {
+				t.Fatalf("expected parking dropped and car heading down, got %s (parking=%v)",
+					car.CurrentState(), car.Parking())
+			}
+			stops := runCarStops(car, 20)
+			if !intSliceEqual(stops, []int{2}) || car.Floor() != 2 {
+				t.Errorf("expected single stop at 2, got %v ending at %d", stops, car.Floor())
+			}
+		})
+	}
+}
+
+func TestScheduleParking_Modes(t *testing.T) {
+	s := NewPeakSchedule()
+	tests := []struct {
+		at   time.Duration
+		want ParkMode
+	}{
+		{8 * time.Hour, ParkLobby},
+		{17*time.Hour + 30*time.Minute, ParkUpper},
+		{12 * time.Hour, ParkSpread},
+		{10 * time.Hour, ParkSpread},            // To is exclusive
+		{24*time.Hour + 8*time.Hour, ParkLobby}, // wraps to the next day
+	}
+	for _, tt := range tests {
+		if got := s.Mode(tt.at); got != tt.want {
+			t.Errorf("Mode(%v) = %s, want %s", tt.at, got, tt.want)
+		}
+	}
+
+	d := NewDispatcher(2, 1, 10)
+	if got := s.HomeFloors(d, 12*time.Hour, 2); !intSliceEqual(got, []int{3, 8}) {
+		t.Errorf("spread homes = %v, want [3 8]", got)
+	}
+	if got := s.HomeFloors(d, 17*time.Hour, 2); !intSliceEqual(got, []int{7, 9}) {
+		t.Errorf("upper homes = %v, want [7 9]", got)
+	}
+}
+
+func TestDispatcher_ParkIdle_MorningLobby(t *testing.T) {
+	d := NewDispatcher(3, 1, 10, WithParking(NewPeakSchedule()))
+	elevatorAt(d, 0).CurrentFloor = 5
+	elevatorAt(d, 1).CurrentFloor = 9
+	elevatorAt(d, 2).AddRequest(Request{Floor: 7, Type: CabCall}) // busy
+
+	moved := d.ParkIdle(8 * time.Hour)
+	if len(moved) != 2 {
+		t.Fatalf("expected the two idle cars sent home, got %d", len(moved))
+	}
+	for range 20 {
+		d.StepAll()
+	}
+	if elevatorAt(d, 0).CurrentFloor != 1 || elevatorAt(d, 1).CurrentFloor != 1 {
+		t.Errorf("expected idle cars parked at lobby, got %d and %d",
+			elevatorAt(d, 0).CurrentFloor, elevatorAt(d, 1).CurrentFloor)
+	}
+	if elevatorAt(d, 2).CurrentFloor != 7 {
+		t.Errorf("expected busy car to serve its stop, got floor %d", elevatorAt(d, 2).CurrentFloor)
+	}
+}
+
+func TestDispatcher_ParkIdle_NoPolicy(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	if moved := d.ParkIdle(8 * time.Hour); moved != nil {
+		t.Errorf("expected no parking without a policy, got %d moves", len(moved))
+	}
+}
+
+func TestDemandParking_BusiestFloors(t *testing.T) {
+	p := NewDemandParking(5)
+	d := NewDispatcher(2, 1, 10, WithParking(p))
+	for _, f := range []int{2, 7, 7, 4, 7, 4} {
+		d.Dispatch(Request{Floor: f, Direction: DirDown, Type: HallCall})
+	}
+
+	// Window of 5 drops the call at 2.
+	if got := p.HomeFloors(d, 0, 2); !intSliceEqual(got, []int{7, 4}) {
+		t.Errorf("HomeFloors = %v, want [7 4]", got)
+	}
+	if got := NewDemandParking(5).HomeFloors(d, 0, 2); len(got) != 0 {
+		t.Errorf("expected no homes without history, got %v", got)
+	}
+}
+
+func TestSimulator_ParksAfterService(t *testing.T) {
+	d := NewDispatcher(1, 1, 10, WithParking(NewPeakSchedule()))
+	s := NewSimulator(d, time.Second)
+	s.Start = 8 * time.Hour
+	s.AddPassenger(0, 3, 9)
+
+	r := s.Run()
+	if len(r.Passengers) != 1 {
+		t.Fatalf("expected passenger served, got %d", len(r.Passengers))
+	}
+	car := d.Elevators[0]
+	if car.Floor() != 1 || car.CurrentState() != StateIdle {
+		t.Errorf("expected car parked at lobby, got %s at %d", car.CurrentState(), car.Floor())
+	}
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"slices"
+	"time"
+)
+
+const passengerWeight = 10 // Default weight of a passenger
+
+// PassengerState tracks where a passenger is in their journey.
+type PassengerState int
+
+const (
+	PassengerWaiting   PassengerState = iota // Hall call placed, waiting at the origin
+	PassengerRiding                          // On board, cab call registered
+	PassengerArrived                         // Alighted at the destination
+	PassengerEvacuated                       // Left the car or the hall in an emergency, trip unfinished
+)
+
+func (s PassengerState) String() string {
+	switch s {
+	case PassengerRiding:
+		return "Riding"
+	case PassengerArrived:
+		return "Arrived"
+	case PassengerEvacuated:
+		return "Evacuated"
+	default:
+		return "Waiting"
+	}
+}
+
+// Passenger is a single trip through the building.
+//
+// Cars move passengers through their states: a passenger boards when the car
+// serves its hall call, registers a cab call for its destination, and alights
+// there. Times are filled in by whoever owns the clock (see Simulator).
+type Passenger struct {
+	ID          int
+	Origin      int
+	Destination int
+	Weight      int
+	Priority    int // PriorityVIP: served ahead of the LOOK order
+	State       PassengerState
+
+	ArrivalTime time.Duration // pressed the hall button
+	BoardTime   time.Duration // stepped into the car
+	AlightTime  time.Duration // stepped out at the destination
+}
+
+// NewPassenger creates a passenger of default weight.
+func NewPassenger(id, origin, destination int, arrival time.Duration) *Passenger {
+	return &Passenger{
+		ID:          id,
+		Origin:      origin,
+		Destination: destination,
+		Weight:      passengerWeight,
+		ArrivalTime: arrival,
+	}
+}
+
+// Direction returns the hall button the passenger presses.
+func (p *Passenger) Direction() Direction {
+	if p.Destination > p.Origin {
+		return DirUp
+	}
+	return DirDown
+}
+
+// HallRequest returns the hall call the passenger places at the origin floor.
+func (p *Passenger) HallRequest() Request {
+	return Request{Floor: p.Origin, Direction: p.Direction(), Type: HallCall, Priority: p.Priority}
+}
+
+// CabRequest returns the cab call the passenger registers after boarding.
+func (p *Passenger) CabRequest() Request {
+	return Request{Floor: p.Destination, Type: CabCall, Priority: p.Priority}
+}
+
+// WaitTime is the time between pressing the hall button and boarding.
+func (p *Passenger) WaitTime() time.Duration { return p.BoardTime - p.ArrivalTime }
+
+// RideTime is the time spent inside the car.
+func (p *Passenger) RideTime() time.Duration { return p.AlightTime - p.BoardTime }
+
+// JourneyTime is the total time from pressing the hall button to arriving.
+func (p *Passenger) JourneyTime() time.Duration { return p.AlightTime - p.ArrivalTime }
+
+// cabin tracks who is inside a car and who is waiting for it.
+// Occupancy does not depend on how stops are stored, so every car
+// implementation embeds the same cabin.
+type cabin struct {
+	occupants []*Passenger
+	waiting   []*Passenger // assigned to this car, not yet boarded
+
+	currentWeight int // sum of occupant weights
+	capacity      Capacity
+
+	antiNuisance bool // cancel cab calls when the car closes its door empty at a terminal floor
+}
+
+func newCabin() cabin {
+	return cabin{capacity: DefaultCapacity()}
+}
+
+// Occupants returns the passengers currently inside the car.
+func (c *cabin) Occupants() []*Passenger {
+	return slices.Clone(c.occupants)
+}
+
+// Waiting returns the passengers assigned to the car but not yet on board.
+func (c *cabin) Waiting() []*Passenger {
+	return slices.Clone(c.waiting)
+}
+
+// await registers a passenger that will board at its origin floor.
+func (c *cabin) await(p *Passenger) {
+	p.State = PassengerWaiting
+	c.waiting = append(c.waiting, p)
+}
+
+// admit puts a passenger on board.
+func (c *cabin) admit(p *Passenger) {
+	p.State = PassengerRiding
+	c.occupants = append(c.occupants, p)
+	c.currentWeight += p.Weight
+}
+
+// alight lets out every occupant whose destination is floor.
+func (c *cabin) alight(floor int) {
+	kept := c.occupants[:0]
+	for _, p := range c.occupants {
+		if p.Destination == floor {
+			p.State = PassengerArrived
+			c.currentWeight -= p.Weight
+			continue
+		}
+		kept = append(kept, p)
+	}
+	c.occupants = kept
+}
+
+// board admits the waiting passengers at floor travelling in one of the
+// served directions, in the order they were assigned, and returns them so the
+// car can register their cab calls. Boarding stops once the car is over
+// capacity: the first passenger who does not fit gets on and sets off the
+// overload alarm, the rest keep waiting.
+func (c *cabin) board(floor int, up, down bool) []*Passenger {
+	var boarded []*Passenger
+	kept := c.waiting[:0]
+	for _, p := range c.waiting {
+		dir := p.Direction()
+		if p.Origin == floor && ((dir == DirUp && up) || (dir == DirDown && down)) && c.fits(p) && !c.overloaded() {
+			c.admit(p)
+			boarded = append(boarded, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	c.waiting = kept
+	return boarded
+}
+
+// release removes and returns the waiting passengers at floor going dir.
+func (c *cabin) release(floor int, dir Direction) []*Passenger {
+	var released []*Passenger
+	kept := c.waiting[:0]
+	for _, p := range c.waiting {
+		if p.Origin == floor && p.Direction() == dir {
+			released = append(released, p)
+			continue
+		}
+		kept = append(kept, p)
+	}
+	c.waiting = kept
+	return released
+}
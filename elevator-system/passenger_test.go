@@ -0,0 +1,106 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestPassenger_BoardsAndAlights(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			p := NewPassenger(1, 4, 8, 0)
+
+			car.AddPassenger(p)
+			if p.State != PassengerWaiting || len(car.Waiting()) != 1 {
+				t.Fatalf("expected passenger waiting, got %s (waiting=%d)", p.State, len(car.Waiting()))
+			}
+
+			// Step until the car has picked the passenger up.
+			for range 20 {
+				car.Step()
+				if p.State == PassengerRiding {
+					break
+				}
+			}
+			if p.State != PassengerRiding || car.Floor() != 4 {
+				t.Fatalf("expected passenger riding from floor 4, got %s at floor %d", p.State, car.Floor())
+			}
+			if got := car.Occupants(); len(got) != 1 || got[0] != p {
+				t.Fatalf("expected passenger on board, got %v", got)
+			}
+			if up, _ := car.StopsCabSnapshot(); !intSliceEqual(up, []int{8}) {
+				t.Errorf("expected cab call registered for floor 8, got %v", up)
+			}
+
+			runCarUntilIdle(car, 50)
+
+			if p.State != PassengerArrived || car.Floor() != 8 {
+				t.Errorf("expected passenger arrived at 8, got %s at floor %d", p.State, car.Floor())
+			}
+			if len(car.Occupants()) != 0 {
+				t.Errorf("expected empty car, got %d occupants", len(car.Occupants()))
+			}
+		})
+	}
+}
+
+func TestPassenger_WeightDerivedFromOccupants(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	a := &Passenger{ID: 1, Origin: 1, Destination: 5, Weight: 70}
+	b := &Passenger{ID: 2, Origin: 1, Destination: 3, Weight: 30}
+
+	e.AddPassenger(a) // car is at floor 1: door opens, a boards at once
+	e.AddPassenger(b)
+	if e.currentWeight != 100 || !e.WeightSensor() {
+		t.Fatalf("expected weight 100 (full), got %d", e.currentWeight)
+	}
+
+	for range 20 {
+		e.Step()
+		if b.State == PassengerArrived {
+			break
+		}
+	}
+	if e.currentWeight != 70 || e.WeightSensor() {
+		t.Errorf("expected weight 70 after b alights, got %d", e.currentWeight)
+	}
+
+	runUntilIdle(e, 50)
+	if e.currentWeight != 0 {
+		t.Errorf("expected empty car to weigh 0, got %d", e.currentWeight)
+	}
+}
+
+func TestPassenger_OnlyServedDirectionBoards(t *testing.T) {
+	e := NewElevator(1, 1, 10)
+	up := NewPassenger(1, 5, 9, 0)
+	down := NewPassenger(2, 5, 2, 0)
+	e.AddRequest(Request{Floor: 7, Type: CabCall})
+	e.AddPassenger(up)
+	e.AddPassenger(down)
+
+	// First stop at 5 going up: only the up passenger boards.
+	for range 20 {
+		e.Step()
+		if e.State == StateDoorOpen {
+			break
+		}
+	}
+	if e.CurrentFloor != 5 || up.State != PassengerRiding || down.State != PassengerWaiting {
+		t.Fatalf("expected only up passenger to board at 5, got floor=%d up=%s down=%s",
+			e.CurrentFloor, up.State, down.State)
+	}
+
+	runUntilIdle(e, 100)
+	if up.State != PassengerArrived || down.State != PassengerArrived {
+		t.Errorf("expected both passengers delivered, got up=%s down=%s", up.State, down.State)
+	}
+}
+
+func TestPassenger_JourneyTimes(t *testing.T) {
+	p := &Passenger{ArrivalTime: 10, BoardTime: 25, AlightTime: 70}
+
+	if p.WaitTime() != 15 || p.RideTime() != 45 || p.JourneyTime() != 60 {
+		t.Errorf("unexpected times: wait=%v ride=%v journey=%v", p.WaitTime(), p.RideTime(), p.JourneyTime())
+	}
+}
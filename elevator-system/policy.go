@@ -0,0 +1,144 @@
+package main
+
+import (
+	"math"
+	"time"
+)
+
+// DispatchPolicy chooses which car serves a request.
+// cars holds the candidates (never empty); d gives access to the building range.
+type DispatchPolicy interface {
+	Select(d *Dispatcher, cars []CarModel, r Request) CarModel
+}
+
+const defaultLoadWeight = 0.5 // Cost added per pending stop in CostPolicy
+
+// CostPolicy picks the car with the lowest cost.
+//
+// Cost formula:
+//
+//	base = |currentFloor - requestFloor|
+//	if elevator is idle: cost = base
+//	if elevator is moving toward the request and same direction: cost = base
+//	if elevator is moving toward but opposite direction: cost = base + N/2
+//	if elevator is moving away: cost = distance_to_end + end_to_request
+//
+// LoadWeight is added for each pending request to prefer less-loaded elevators.
+//
+// With Timed set the cost is in seconds from each car's Kinematics: the
+// floors above take TravelTime, and each pending stop costs a DoorCycle for
+// one passenger in place of LoadWeight. Cars of different speeds then
+// compete fairly.
+type CostPolicy struct {
+	LoadWeight float64
+	Timed      bool
+}
+
+// NewCostPolicy returns the cost policy with the default load weight.
+func NewCostPolicy() *CostPolicy {
+	return &CostPolicy{LoadWeight: defaultLoadWeight}
+}
+
+func (p *CostPolicy) Select(d *Dispatcher, cars []CarModel, r Request) CarModel {
+	var best CarModel
+	bestCost := math.MaxFloat64
+	for _, e := range cars {
+		if c := p.cost(d, e, r); c < bestCost {
+			bestCost = c
+			best = e
+		}
+	}
+	return best
+}
+
+// cost calculates the cost for an elevator to serve a request.
+func (p *CostPolicy) cost(d *Dispatcher, e CarModel, r Request) float64 {
+	floors := p.floors(d, e, r)
+	if p.Timed {
+		k := e.Kinematics()
+		stops := time.Duration(e.PendingCount()) * k.DoorCycle(1)
+		return (k.travel(floors*k.FloorHeight) + stops).Seconds()
+	}
+	return floors + p.LoadWeight*float64(e.PendingCount())
+}
+
+// floors is how many floors e travels before it can serve r.
+func (p *CostPolicy) floors(d *Dispatcher, e CarModel, r Request) float64 {
+	floor, dir := e.Floor(), e.CurrentDirection()
+	distance := abs(floor - r.Floor)
+
+	// Idle elevator: pure distance. A parking car drops its move for a real call.
+	if e.CurrentState() == StateIdle || dir == DirIdle || e.Parking() {
+		return float64(distance)
+	}
+
+	movingToward := (dir == DirUp && r.Floor >= floor) ||
+		(dir == DirDown && r.Floor <= floor)
+
+	if movingToward {
+		sameDir := r.Type == CabCall || r.Direction == dir
+		if sameDir {
+			// Best case: on the way and same direction.
+			return float64(distance)
+		}
+		// On the way but opposite direction — will pass through but won't pick up.
+		// Needs to go to end first, then come back.
+		span := float64(d.MaxFloor - d.MinFloor)
+		return float64(distance) + span/2
+	}
+
+	// Moving away: must go to end, reverse, then reach the floor.
+	var detour int
+	if dir == DirUp {
+		detour = (d.MaxFloor - floor) + (d.MaxFloor - r.Floor)
+	} else {
+		detour = (floor - d.MinFloor) + (r.Floor - d.MinFloor)
+	}
+	return float64(detour)
+}
+
+// NearestCarPolicy picks the car closest to the request floor, ignoring
+// direction. Ties go to the car with fewer pending stops.
+type NearestCarPolicy struct{}
+
+func (NearestCarPolicy) Select(_ *Dispatcher, cars []CarModel, r Request) CarModel {
+	best := cars[0]
+	for _, e := range cars[1:] {
+		dist, bestDist := abs(e.Floor()-r.Floor), abs(best.Floor()-r.Floor)
+		if dist < bestDist || (dist == bestDist && e.PendingCount() < best.PendingCount()) {
+			best = e
+		}
+	}
+	return best
+}
+
+// RoundRobinPolicy hands requests to the cars in turn, regardless of position.
+type RoundRobinPolicy struct {
+	next int
+}
+
+func (p *RoundRobinPolicy) Select(_ *Dispatcher, cars []CarModel, _ Request) CarModel {
+	e := cars[p.next%len(cars)]
+	p.next++
+	return e
+}
+
+// ZonePolicy splits the building into one contiguous zone per car and sends
+// each request to the owner of its floor: car i serves the i-th zone from
+// the bottom. If the owner is not a candidate, the nearest candidate is used.
+type ZonePolicy struct{}
+
+func (ZonePolicy) Select(d *Dispatcher, cars []CarModel, r Request) CarModel {
+	n := len(d.Elevators)
+	floors := d.MaxFloor - d.MinFloor + 1
+	size := (floors + n - 1) / n // ceil(floors / n)
+	zone := max(0, min((r.Floor-d.MinFloor)/size, n-1))
+
+	owner := d.Elevators[zone]
+	for _, e := range cars {
+		if e == owner {
+			return e
+		}
+	}
+	return NearestCarPolicy{}.Select(d, cars, r)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCostPolicy_IsDefault(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+
+	p, ok := d.Policy.(*CostPolicy)
+	if !ok {
+		t.Fatalf("expected *CostPolicy by default, got %T", d.Policy)
+	}
+	if p.LoadWeight != defaultLoadWeight {
+		t.Errorf("expected load weight %v, got %v", defaultLoadWeight, p.LoadWeight)
+	}
+}
+
+func TestCostPolicy_LoadWeight(t *testing.T) {
+	// Two idle cars at the same distance; car 1 still holds two stops.
+	newFleet := func(policy DispatchPolicy) *Dispatcher {
+		d := NewDispatcher(2, 1, 10, WithPolicy(policy))
+		busy := elevatorAt(d, 0)
+		busy.CurrentFloor = 4
+		busy.hallDownStops[busy.idx(2)] = true
+		busy.hallDownStops[busy.idx(1)] = true
+		elevatorAt(d, 1).CurrentFloor = 6
+		return d
+	}
+
+	if chosen := newFleet(&CostPolicy{LoadWeight: 0}).Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}); chosen.CarID() != 1 {
+		t.Errorf("without load weight expected first car on tie, got %d", chosen.CarID())
+	}
+	if chosen := newFleet(NewCostPolicy()).Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}); chosen.CarID() != 2 {
+		t.Errorf("with load weight expected less-loaded car 2, got %d", chosen.CarID())
+	}
+}
+
+func TestNearestCarPolicy(t *testing.T) {
+	d := NewDispatcher(3, 1, 10, WithPolicy(NearestCarPolicy{}))
+	elevatorAt(d, 0).CurrentFloor = 1
+	elevatorAt(d, 1).CurrentFloor = 4
+	elevatorAt(d, 1).State = StateMovingDown
+	elevatorAt(d, 1).Direction = DirDown
+	elevatorAt(d, 2).CurrentFloor = 9
+
+	// Car 2 is moving away from floor 5 but is still the nearest.
+	chosen := d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall})
+	if chosen.CarID() != 2 {
+		t.Errorf("expected nearest car 2, got %d", chosen.CarID())
+	}
+}
+
+func TestRoundRobinPolicy(t *testing.T) {
+	d := NewDispatcher(3, 1, 10, WithPolicy(&RoundRobinPolicy{}))
+
+	var got []int
+	for range 5 {
+		got = append(got, d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall}).CarID())
+	}
+
+	expected := []int{1, 2, 3, 1, 2}
+	if !intSliceEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestZonePolicy(t *testing.T) {
+	d := NewDispatcher(3, 1, 12, WithPolicy(ZonePolicy{}))
+	// Zones: car 1 → 1-4, car 2 → 5-8, car 3 → 9-12, wherever the cars are.
+	elevatorAt(d, 0).CurrentFloor = 12
+	elevatorAt(d, 2).CurrentFloor = 1
+
+	cases := []struct {
+		floor, car int
+	}{
+		{1, 1}, {4, 1}, {5, 2}, {8, 2}, {9, 3}, {12, 3},
+	}
+	for _, c := range cases {
+		if got := d.Policy.Select(d, d.Elevators, Request{Floor: c.floor, Direction: DirUp, Type: HallCall}); got.CarID() != c.car {
+			t.Errorf("floor %d: expected car %d, got %d", c.floor, c.car, got.CarID())
+		}
+	}
+}
+
+func TestZonePolicy_OwnerNotCandidate(t *testing.T) {
+	d := NewDispatcher(2, 1, 10, WithPolicy(ZonePolicy{}))
+	elevatorAt(d, 1).CurrentFloor = 3
+
+	// Floor 2 belongs to car 1, but only car 2 is a candidate.
+	got := d.Policy.Select(d, d.Elevators[1:], Request{Floor: 2, Direction: DirUp, Type: HallCall})
+	if got.CarID() != 2 {
+		t.Errorf("expected fallback to car 2, got %d", got.CarID())
+	}
+}
+
+func TestPolicies_ABOnSameTraffic(t *testing.T) {
+	arrivals := NewTrafficGenerator(TrafficLunch, 1, 20, 5).Generate(time.Hour, 8)
+
+	policies := map[string]DispatchPolicy{
+		"cost":        NewCostPolicy(),
+		"nearest":     NearestCarPolicy{},
+		"round-robin": &RoundRobinPolicy{},
+		"zone":        ZonePolicy{},
+	}
+	for name, policy := range policies {
+		s := NewSimulator(NewDispatcher(4, 1, 20, WithPolicy(policy)), time.Second)
+		s.AddArrivals(arrivals)
+
+		r := s.Run()
+		if len(r.Passengers) != len(arrivals) || r.Pending != 0 {
+			t.Errorf("%s: expected %d trips, got %d (pending %d)", name, len(arrivals), len(r.Passengers), r.Pending)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package main
+
+import "slices"
+
+// Request priorities.
+const (
+	PriorityNormal = 0
+	PriorityVIP    = 1
+)
+
+// vipStreakLimit is how many VIP stops a car may serve in a row before it
+// must open its door for a normal stop again. It bounds how long a stream of
+// VIP calls can starve the LOOK order.
+const vipStreakLimit = 3
+
+// vipQueue holds the VIP stops a car serves ahead of its LOOK order.
+//
+// The stops themselves live in the car's stop sets like any other; the queue
+// only decides which of them to run to first. While a VIP target is active
+// the car runs express: it heads straight for the target and skips normal
+// stops on the way.
+type vipQueue struct {
+	vipFloors []int // in arrival order
+	vipStreak int   // VIP stops served since the last normal stop
+}
+
+// HasVIPRequests reports whether a VIP stop is still pending.
+func (q *vipQueue) HasVIPRequests() bool {
+	return len(q.vipFloors) > 0
+}
+
+// vipTarget returns the VIP floor to head for, unless the car has used up
+// its streak and must serve a normal stop first.
+func (q *vipQueue) vipTarget() (int, bool) {
+	if len(q.vipFloors) == 0 || q.vipStreak >= vipStreakLimit {
+		return 0, false
+	}
+	return q.vipFloors[0], true
+}
+
+func (q *vipQueue) pushVIP(floor int) {
+	if !slices.Contains(q.vipFloors, floor) {
+		q.vipFloors = append(q.vipFloors, floor)
+	}
+}
+
+// vipServed records a door opening at floor: a VIP stop extends the streak,
+// any other stop resets it.
+func (q *vipQueue) vipServed(floor int) {
+	i := slices.Index(q.vipFloors, floor)
+	if i < 0 {
+		q.vipStreak = 0
+		return
+	}
+	q.vipFloors = slices.Delete(q.vipFloors, i, i+1)
+	q.vipStreak++
+}
+
+// dropVIP forgets a VIP stop that was removed without being served.
+func (q *vipQueue) dropVIP(floor int) {
+	if i := slices.Index(q.vipFloors, floor); i >= 0 {
+		q.vipFloors = slices.Delete(q.vipFloors, i, i+1)
+	}
+}
+
+// reserveVIPCar picks the nearest idle, empty car serving floors for a VIP
+// call and keeps it out of normal dispatch until its VIP stops are served.
+// Returns nil if no such car is available.
+func (d *Dispatcher) reserveVIPCar(r Request, floors []int) CarModel {
+	var best CarModel
+	for _, e := range serving(d.available(), floors...) {
+		if e.CurrentState() != StateIdle || e.HasPendingRequests() {
+			continue
+		}
+		if best == nil || abs(e.Floor()-r.Floor) < abs(best.Floor()-r.Floor) {
+			best = e
+		}
+	}
+	if best != nil {
+		if d.reserved == nil {
+			d.reserved = make(map[CarModel]bool)
+		}
+		d.reserved[best] = true
+	}
+	return best
+}
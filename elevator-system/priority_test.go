@@ -0,0 +1,172 @@
+package main
+
+import (
+	"testing"
+)
+
+// runCarStops drives any car until it is idle with nothing pending and
+// returns the floors where its door opened.
+func runCarStops(car CarModel, maxSteps int) []int {
+	var stops []int
+	for range maxSteps {
+		before := car.CurrentState()
+		car.Step()
+		if car.CurrentState() == StateDoorOpen && before != StateDoorOpen {
+			stops = append(stops, car.Floor())
+		}
+		if car.CurrentState() == StateIdle && !car.HasPendingRequests() {
+			break
+		}
+	}
+	return stops
+}
+
+func TestPriority_VIPPreemptsLOOK(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 8, Type: CabCall})
+			for range 4 {
+				car.Step()
+			}
+			if car.Floor() != 5 || car.CurrentState() != StateMovingUp {
+				t.Fatalf("expected car moving up at 5, got %s at %d", car.CurrentState(), car.Floor())
+			}
+
+			// LOOK would finish the up sweep to 8 first; the VIP call turns the car around.
+			car.AddRequest(Request{Floor: 2, Direction: DirUp, Type: HallCall, Priority: PriorityVIP})
+			if car.CurrentState() != StateMovingDown {
+				t.Errorf("expected car to turn toward VIP stop, got %s", car.CurrentState())
+			}
+
+			stops := runCarStops(car, 50)
+			if !intSliceEqual(stops, []int{2, 8}) {
+				t.Errorf("expected stops [2 8], got %v", stops)
+			}
+			if car.HasVIPRequests() {
+				t.Error("expected VIP stop served")
+			}
+		})
+	}
+}
+
+func TestPriority_VIPRunsExpress(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 3, Direction: DirUp, Type: HallCall})
+			car.AddRequest(Request{Floor: 6, Type: CabCall, Priority: PriorityVIP})
+
+			// The normal stop at 3 is on the way but skipped until the VIP is served.
+			stops := runCarStops(car, 50)
+			if !intSliceEqual(stops, []int{6, 3}) {
+				t.Errorf("expected stops [6 3], got %v", stops)
+			}
+		})
+	}
+}
+
+func TestPriority_VIPAtCurrentFloor(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			car.AddRequest(Request{Floor: 1, Direction: DirUp, Type: HallCall, Priority: PriorityVIP})
+
+			if car.CurrentState() != StateDoorOpen || car.HasVIPRequests() {
+				t.Errorf("expected VIP served immediately, got %s (vip pending=%v)",
+					car.CurrentState(), car.HasVIPRequests())
+			}
+		})
+	}
+}
+
+func TestPriority_NormalStopsNotStarved(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 10)
+			p := NewPassenger(1, 5, 7, 0)
+			car.AddPassenger(p)
+
+			// Keep a VIP call pending at all times, alternating between the
+			// terminal floors, so the car always has a reason to skip floor 5.
+			vipFloors := []int{10, 1}
+			next := 0
+			for step := range 200 {
+				if !car.HasVIPRequests() {
+					f := vipFloors[next%len(vipFloors)]
+					if f == car.Floor() {
+						next++
+						f = vipFloors[next%len(vipFloors)]
+					}
+					car.AddRequest(Request{Floor: f, Type: CabCall, Priority: PriorityVIP})
+					next++
+				}
+				car.Step()
+				if p.State != PassengerWaiting {
+					t.Logf("passenger boarded after %d steps", step+1)
+					return
+				}
+			}
+			t.Fatal("normal hall call starved by continuous VIP traffic")
+		})
+	}
+}
+
+func TestDispatcher_VIPReservesIdleCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	vipCar := d.Dispatch(Request{Floor: 6, Direction: DirUp, Type: HallCall, Priority: PriorityVIP})
+	if vipCar == nil || vipCar.CarID() != 1 {
+		t.Fatalf("expected car 1 reserved for VIP, got %v", vipCar)
+	}
+	vipCar.Step()
+	vipCar.Step()
+
+	// Car 1 is closer and moving up, but it is held for the VIP.
+	chosen := d.Dispatch(Request{Floor: 4, Direction: DirUp, Type: HallCall})
+	if chosen.CarID() != 2 {
+		t.Errorf("expected normal call to skip the reserved car, got car %d", chosen.CarID())
+	}
+
+	for range 10 {
+		vipCar.Step()
+		if !vipCar.HasVIPRequests() {
+			break
+		}
+	}
+	if vipCar.HasVIPRequests() {
+		t.Fatal("expected VIP stop served")
+	}
+
+	// Reservation ends once the VIP stop is served.
+	chosen = d.Dispatch(Request{Floor: 7, Direction: DirUp, Type: HallCall})
+	if chosen.CarID() != 1 {
+		t.Errorf("expected released car 1 for call at 7, got car %d", chosen.CarID())
+	}
+}
+
+func TestDispatcher_VIPWithoutIdleCarPreempts(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	d.Dispatch(Request{Floor: 9, Direction: DirDown, Type: HallCall})
+	d.StepAll()
+
+	// No idle car: the VIP goes to the busy one and jumps its queue.
+	car := d.Dispatch(Request{Floor: 4, Direction: DirUp, Type: HallCall, Priority: PriorityVIP})
+	if car == nil || !car.HasVIPRequests() {
+		t.Fatalf("expected VIP assigned to the busy car, got %v", car)
+	}
+
+	stops := runCarStops(car, 100)
+	if !intSliceEqual(stops, []int{4, 9}) {
+		t.Errorf("expected stops [4 9], got %v", stops)
+	}
+}
+
+func TestDispatcher_AllCarsReservedFallsBack(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	car := d.Dispatch(Request{Floor: 4, Direction: DirUp, Type: HallCall, Priority: PriorityVIP})
+
+	// The only car is reserved; normal calls still reach it.
+	if chosen := d.Dispatch(Request{Floor: 6, Direction: DirUp, Type: HallCall}); chosen != car {
+		t.Errorf("expected fallback to the reserved car, got %v", chosen)
+	}
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"cmp"
+	"slices"
+)
+
+// hallCallKey identifies one hall call held by one car. Destination dispatch
+// can give the same floor and direction to several cars, so the car is part
+// of the key.
+type hallCallKey struct {
+	car   CarModel
+	floor int
+	dir   Direction
+}
+
+// hallCall is an outstanding hall call in the dispatcher's registry.
+type hallCall struct {
+	req Request
+	age int // StepAll ticks since the call was (re)assigned
+}
+
+// HallCallStatus describes an outstanding hall call.
+type HallCallStatus struct {
+	Floor     int
+	Direction Direction
+	CarID     int
+	Age       int
+}
+
+// WithCallTimeout reassigns a hall call that has waited timeout StepAll ticks
+// on the same car (default 0: never reassign for delay).
+func WithCallTimeout(timeout int) DispatcherOption {
+	return func(d *Dispatcher) { d.CallTimeout = timeout }
+}
+
+// register records that car now holds hall call r.
+func (d *Dispatcher) register(car CarModel, r Request) {
+	if r.Type != HallCall {
+		return
+	}
+	if d.calls == nil {
+		d.calls = make(map[hallCallKey]*hallCall)
+	}
+	d.calls[hallCallKey{car, r.Floor, r.Direction}] = &hallCall{req: r}
+}
+
+// stepCar steps car once. A car sounding its overload alarm has put a
+// passenger back on the landing, with the hall call LOOK had already served;
+// the call is registered again so Reassign can move it to a car with room.
+func (d *Dispatcher) stepCar(car CarModel) []StepEvent {
+	evs := car.StepEvents()
+	if body, _ := stepBody(evs); body[0].Kind != StepOverloadAlarm {
+		return evs
+	}
+	for _, p := range car.Waiting() {
+		r := p.HallRequest()
+		if _, ok := d.calls[hallCallKey{car, r.Floor, r.Direction}]; !ok && p.Origin == car.Floor() {
+			d.register(car, r)
+		}
+	}
+	return evs
+}
+
+// OutstandingCalls returns the hall calls assigned but not yet served,
+// ordered by floor, direction and car.
+func (d *Dispatcher) OutstandingCalls() []HallCallStatus {
+	d.pruneCalls()
+	calls := make([]HallCallStatus, 0, len(d.calls))
+	for k, c := range d.calls {
+		calls = append(calls, HallCallStatus{Floor: k.floor, Direction: k.dir, CarID: k.car.CarID(), Age: c.age})
+	}
+	slices.SortFunc(calls, func(a, b HallCallStatus) int {
+		return cmp.Or(cmp.Compare(a.Floor, b.Floor), cmp.Compare(a.Direction, b.Direction), cmp.Compare(a.CarID, b.CarID))
+	})
+	return calls
+}
+
+// Reassign moves outstanding hall calls off cars that cannot be trusted to
+// serve them:
+//   - removed: the car is in maintenance
+//   - failed: the watchdog took the car out of service
+//   - overloaded: the car's weight sensor trips, so LOOK skips its hall stops
+//   - delayed: the call has waited CallTimeout ticks on the car
+//
+// A call moves only if another car in service can take it; otherwise it stays
+// where it is rather than being dropped. Returns the number of calls moved.
+func (d *Dispatcher) Reassign() int {
+	d.pruneCalls()
+	keys := make([]hallCallKey, 0, len(d.calls))
+	for k := range d.calls {
+		keys = append(keys, k)
+	}
+	// Map order is random; keep reassignment deterministic.
+	slices.SortFunc(keys, func(a, b hallCallKey) int {
+		return cmp.Or(cmp.Compare(a.floor, b.floor), cmp.Compare(a.dir, b.dir), cmp.Compare(a.car.CarID(), b.car.CarID()))
+	})
+
+	moved := 0
+	for _, k := range keys {
+		c, ok := d.calls[k]
+		if !ok {
+			continue
+		}
+		delayed := d.CallTimeout > 0 && c.age >= d.CallTimeout
+		if !k.car.InMaintenance() && !d.failed[k.car] && !k.car.WeightSensor() && !delayed {
+			continue
+		}
+		if d.redispatch(k.car, c.req) {
+			moved++
+		}
+	}
+	return moved
+}
+
+// tickCalls ages every outstanding call by one StepAll tick and reassigns
+// those that need it.
+func (d *Dispatcher) tickCalls() {
+	for _, c := range d.calls {
+		c.age++
+	}
+	d.Reassign()
+}
+
+// pruneCalls forgets calls their car has served.
+func (d *Dispatcher) pruneCalls() {
+	for k := range d.calls {
+		if !awaitingPickup(k.car, k.floor, k.dir) {
+			delete(d.calls, k)
+		}
+	}
+}
+
+// selectCarExcept picks a new car for a call moved off skip: the policy's
+// choice among the other cars that are not overloaded and serve r.Floor and
+// the waiting passengers' destinations, preferring cars not reserved for a
+// VIP. Returns nil if there are none.
+func (d *Dispatcher) selectCarExcept(r Request, skip CarModel, dests ...int) CarModel {
+	floors := append([]int{r.Floor}, dests...)
+	cars := serving(takeOver(d.available(), skip), floors...)
+	if len(cars) == 0 {
+		cars = serving(takeOver(d.inService(), skip), floors...)
+	}
+	if len(cars) == 0 {
+		return nil
+	}
+	return d.Policy.Select(d, cars, r)
+}
+
+// takeOver filters cars down to those able to take over a call from skip.
+func takeOver(cars []CarModel, skip CarModel) []CarModel {
+	return slices.DeleteFunc(cars, func(e CarModel) bool {
+		return e == skip || e.WeightSensor()
+	})
+}
+
+// callRequest returns the request car was given for the hall call at
+// floor/dir, or a plain hall call if it is not in the registry.
+func (d *Dispatcher) callRequest(car CarModel, floor int, dir Direction) Request {
+	if c, ok := d.calls[hallCallKey{car, floor, dir}]; ok {
+		return c.req
+	}
+	return Request{Floor: floor, Direction: dir, Type: HallCall}
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRegistry_TracksUntilServed(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall}) // served on the spot
+	d.Dispatch(Request{Floor: 4, Direction: DirUp, Type: HallCall})
+
+	calls := d.OutstandingCalls()
+	if len(calls) != 1 || calls[0].Floor != 4 || calls[0].CarID != 1 {
+		t.Fatalf("expected one outstanding call at 4 on car 1, got %+v", calls)
+	}
+
+	for range 20 {
+		d.StepAll()
+	}
+	if calls := d.OutstandingCalls(); len(calls) != 0 {
+		t.Errorf("expected registry empty after service, got %+v", calls)
+	}
+}
+
+func TestRegistry_ReassignsFromOverloadedCar(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 1).CurrentFloor = 10
+
+	p := NewPassenger(1, 5, 9, 0)
+	full := elevatorAt(d, 0)
+	if d.DispatchPassenger(p) != full {
+		t.Fatal("expected car 1 assigned first")
+	}
+
+	// Car 1 fills up: it would pass floor 5 on the way up without stopping.
+	boardRider(full, full.Capacity().MaxWeight, 8)
+	d.StepAll()
+
+	calls := d.OutstandingCalls()
+	if len(calls) != 1 || calls[0].CarID != 2 {
+		t.Fatalf("expected the call moved to car 2, got %+v", calls)
+	}
+	if w := d.Elevators[1].Waiting(); len(w) != 1 || w[0] != p {
+		t.Fatalf("expected passenger waiting on car 2, got %v", w)
+	}
+	if up, _ := full.StopsHallSnapshot(); len(up) != 0 {
+		t.Errorf("expected car 1 to drop the hall stop, still has %v", up)
+	}
+
+	for range 100 {
+		d.StepAll()
+		if d.AllIdle() {
+			break
+		}
+	}
+	if p.State != PassengerArrived {
+		t.Errorf("expected passenger delivered by car 2, got %s", p.State)
+	}
+}
+
+func TestRegistry_OverloadedKeepsCallWithoutAlternative(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	d.Dispatch(Request{Floor: 5, Direction: DirUp, Type: HallCall})
+	boardRider(elevatorAt(d, 0), elevatorAt(d, 0).Capacity().MaxWeight, 8)
+
+	if moved := d.Reassign(); moved != 0 {
+		t.Errorf("expected call kept with no other car, moved %d", moved)
+	}
+	if calls := d.OutstandingCalls(); len(calls) != 1 || calls[0].CarID != 1 {
+		t.Errorf("expected call still on car 1, got %+v", calls)
+	}
+}
+
+func TestRegistry_ReassignsDelayedCall(t *testing.T) {
+	d := NewDispatcher(2, 1, 20, WithCallTimeout(5), WithPolicy(NearestCarPolicy{}))
+	elevatorAt(d, 1).CurrentFloor = 20
+
+	// Car 1 is sent on a long trip up, then given a call far behind it.
+	elevatorAt(d, 0).AddRequest(Request{Floor: 19, Type: CabCall})
+	d.StepAll()
+	car := d.Dispatch(Request{Floor: 1, Direction: DirUp, Type: HallCall})
+	if car.CarID() != 1 {
+		t.Fatalf("expected the nearest car 1, got %d", car.CarID())
+	}
+
+	for range 4 {
+		d.StepAll()
+	}
+	if calls := d.OutstandingCalls(); calls[0].CarID != 1 || calls[0].Age != 4 {
+		t.Fatalf("expected call aged 4 on car 1, got %+v", calls)
+	}
+	d.StepAll()
+
+	calls := d.OutstandingCalls()
+	if len(calls) != 1 || calls[0].CarID != 2 || calls[0].Age != 0 {
+		t.Errorf("expected delayed call moved to car 2 with fresh age, got %+v", calls)
+	}
+}
+
+func TestRegistry_MaintenanceCarSetDirectly(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	elevatorAt(d, 1).CurrentFloor = 10
+	d.Dispatch(Request{Floor: 3, Direction: DirDown, Type: HallCall})
+
+	// Taken out of service behind the dispatcher's back.
+	d.Elevators[0].SetMaintenance(true)
+	d.StepAll()
+
+	if calls := d.OutstandingCalls(); len(calls) != 1 || calls[0].CarID != 2 {
+		t.Errorf("expected the call moved to car 2, got %+v", calls)
+	}
+}
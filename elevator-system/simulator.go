@@ -0,0 +1,473 @@
+package main
+
+import (
+	"container/heap"
+	"time"
+)
+
+// EventKind identifies what a simulation event represents.
+type EventKind int
+
+const (
+	EventPassengerArrival EventKind = iota // Passenger presses a hall button
+	EventCarStep                           // Internal: advance one car by one Step
+	EventCarArrival                        // Car reached a new floor
+	EventDoorOpen                          // Car opened its door (passengers board / alight)
+	EventDoorClose                         // Car closed its door
+)
+
+func (k EventKind) String() string {
+	switch k {
+	case EventPassengerArrival:
+		return "PassengerArrival"
+	case EventCarStep:
+		return "CarStep"
+	case EventCarArrival:
+		return "CarArrival"
+	case EventDoorOpen:
+		return "DoorOpen"
+	default:
+		return "DoorClose"
+	}
+}
+
+// SimEvent is a processed event, reported through Simulator.OnEvent.
+type SimEvent struct {
+	At          time.Duration
+	Kind        EventKind
+	CarID       int // 0 for passenger arrivals that could not be dispatched
+	Floor       int
+	PassengerID int // 0 for car events
+}
+
+// simEvent is an entry in the event queue.
+// seq breaks ties so events at the same instant run in insertion order.
+type simEvent struct {
+	at        time.Duration
+	seq       uint64
+	kind      EventKind
+	car       CarModel
+	passenger *Passenger
+}
+
+// eventQueue is a min-heap of events ordered by (at, seq).
+type eventQueue []*simEvent
+
+func (q eventQueue) Len() int { return len(q) }
+func (q eventQueue) Less(i, j int) bool {
+	if q[i].at != q[j].at {
+		return q[i].at < q[j].at
+	}
+	return q[i].seq < q[j].seq
+}
+func (q eventQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x any)   { *q = append(*q, x.(*simEvent)) }
+func (q *eventQueue) Pop() any {
+	old := *q
+	n := len(old)
+	ev := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return ev
+}
+
+// Simulator is a discrete-event driver for a Dispatcher.
+//
+// Instead of calling StepAll on a fixed clock, it keeps a priority queue of
+// timestamped events. A car only has a step event queued while it has work,
+// so idle periods cost nothing and hours of traffic run in milliseconds.
+// Each car Step takes StepDuration of simulated time, unless Timed is set.
+type Simulator struct {
+	Dispatcher   *Dispatcher
+	StepDuration time.Duration
+	Start        time.Duration // time of day at simulated time zero, for parking schedules
+
+	// Timed takes each step's duration from the car's Kinematics instead of
+	// StepDuration: floors are passed at full speed, a run's acceleration and
+	// braking are charged at the floor it stops at, and the door takes its
+	// open, dwell (scaled by who boards and alights) and close times. A timed
+	// step's effects are visible at once and its events are stamped when it
+	// ends, as a moving car is committed to its next floor.
+	Timed bool
+
+	// OnEvent, if set, is called for every processed event except internal car steps.
+	OnEvent func(SimEvent)
+
+	now       time.Duration
+	queue     eventQueue
+	seq       uint64
+	nextID    int
+	processed int
+
+	scheduled map[CarModel]bool         // car has a step event in the queue
+	faulted   map[CarModel]bool         // car's last step was held by a fault that does not clear itself
+	run       map[CarModel]int          // Timed: floors moved since the car last stopped
+	transfers map[CarModel]int          // Timed: passengers through the door since it opened or last dwelled
+	waiting   map[CarModel][]*Passenger // assigned but not yet boarded
+	riding    map[CarModel][]*Passenger // on board
+	secondLeg map[*Passenger]bool       // changed cars at a sky lobby: keeps its first BoardTime
+	unserved  []*Passenger              // could not be dispatched
+	evacuated []*Passenger              // trip cut short by an emergency
+	done      []*Passenger
+}
+
+// SimReport summarises a simulation run.
+type SimReport struct {
+	Passengers []*Passenger // completed trips, in alighting order
+	Pending    int          // passengers still waiting or riding
+	Unserved   int          // passengers no car could take
+	Evacuated  int          // passengers whose trip an emergency cut short
+	Events     int          // events processed
+	Elapsed    time.Duration
+
+	AvgWait time.Duration
+	MaxWait time.Duration
+	AvgRide time.Duration
+	MaxRide time.Duration
+}
+
+// NewSimulator creates a simulator over the dispatcher's fleet.
+func NewSimulator(d *Dispatcher, stepDuration time.Duration) *Simulator {
+	return &Simulator{
+		Dispatcher:   d,
+		StepDuration: stepDuration,
+		scheduled:    make(map[CarModel]bool),
+		faulted:      make(map[CarModel]bool),
+		run:          make(map[CarModel]int),
+		transfers:    make(map[CarModel]int),
+		waiting:      make(map[CarModel][]*Passenger),
+		riding:       make(map[CarModel][]*Passenger),
+		secondLeg:    make(map[*Passenger]bool),
+	}
+}
+
+// Now returns the current simulated time.
+func (s *Simulator) Now() time.Duration { return s.now }
+
+// AddPassenger schedules a passenger arrival. Returns nil for invalid trips.
+func (s *Simulator) AddPassenger(at time.Duration, origin, destination int) *Passenger {
+	d := s.Dispatcher
+	if origin == destination ||
+		origin < d.MinFloor || origin > d.MaxFloor ||
+		destination < d.MinFloor || destination > d.MaxFloor {
+		return nil
+	}
+	s.nextID++
+	p := NewPassenger(s.nextID, origin, destination, at)
+	s.push(at, EventPassengerArrival, nil, p)
+	return p
+}
+
+// AddArrivals schedules every generated arrival as a passenger.
+func (s *Simulator) AddArrivals(arrivals []Arrival) {
+	for _, a := range arrivals {
+		s.AddPassenger(a.At, a.Origin, a.Destination)
+	}
+}
+
+// Run processes events until the queue is empty.
+func (s *Simulator) Run() SimReport {
+	for s.queue.Len() > 0 {
+		s.process(heap.Pop(&s.queue).(*simEvent))
+	}
+	return s.Report()
+}
+
+// RunUntil processes every event scheduled at or before t.
+func (s *Simulator) RunUntil(t time.Duration) SimReport {
+	for s.queue.Len() > 0 && s.queue[0].at <= t {
+		s.process(heap.Pop(&s.queue).(*simEvent))
+	}
+	if s.now < t {
+		s.now = t
+	}
+	return s.Report()
+}
+
+// Report computes wait and ride statistics over the completed trips.
+func (s *Simulator) Report() SimReport {
+	r := SimReport{
+		Passengers: s.done,
+		Unserved:   len(s.unserved),
+		Evacuated:  len(s.evacuated),
+		Events:     s.processed,
+		Elapsed:    s.now,
+	}
+	for _, ps := range s.waiting {
+		r.Pending += len(ps)
+	}
+	for _, ps := range s.riding {
+		r.Pending += len(ps)
+	}
+	if len(s.done) == 0 {
+		return r
+	}
+	var totalWait, totalRide time.Duration
+	for _, p := range s.done {
+		totalWait += p.WaitTime()
+		totalRide += p.RideTime()
+		r.MaxWait = max(r.MaxWait, p.WaitTime())
+		r.MaxRide = max(r.MaxRide, p.RideTime())
+	}
+	r.AvgWait = totalWait / time.Duration(len(s.done))
+	r.AvgRide = totalRide / time.Duration(len(s.done))
+	return r
+}
+
+func (s *Simulator) push(at time.Duration, kind EventKind, car CarModel, p *Passenger) {
+	s.seq++
+	heap.Push(&s.queue, &simEvent{at: at, seq: s.seq, kind: kind, car: car, passenger: p})
+}
+
+func (s *Simulator) process(ev *simEvent) {
+	s.now = ev.at
+	s.processed++
+
+	switch ev.kind {
+	case EventPassengerArrival:
+		s.handleArrival(ev.passenger)
+	case EventCarStep:
+		s.handleStep(ev.car)
+		return // internal, not reported
+	case EventDoorOpen:
+		s.handleDoorOpen(ev.car)
+	}
+
+	if s.OnEvent != nil {
+		out := SimEvent{At: ev.at, Kind: ev.kind}
+		if ev.car != nil {
+			out.CarID = ev.car.CarID()
+			out.Floor = ev.car.Floor()
+		}
+		if ev.passenger != nil {
+			out.PassengerID = ev.passenger.ID
+			out.Floor = ev.passenger.Origin
+		}
+		s.OnEvent(out)
+	}
+}
+
+// handleArrival hands the passenger to the dispatcher and wakes the chosen car.
+func (s *Simulator) handleArrival(p *Passenger) {
+	s.assign(p, s.Dispatcher.DispatchPassenger(p))
+}
+
+// assign queues p for car and wakes it; a nil car leaves p unserved.
+func (s *Simulator) assign(p *Passenger, car CarModel) {
+	if car == nil {
+		s.unserved = append(s.unserved, p)
+		return
+	}
+	s.waiting[car] = append(s.waiting[car], p)
+
+	// The car was already at this floor: AddRequest reopened the door.
+	if car.CurrentState().loading() && car.Floor() == p.Origin {
+		s.push(s.now, EventDoorOpen, car, nil)
+	}
+	s.wake(car)
+}
+
+// handleStep advances the car one Step and turns the observed transition
+// into arrival / door events when the step ends: at once for untimed steps,
+// after the step's duration for timed ones.
+func (s *Simulator) handleStep(car CarModel) {
+	s.scheduled[car] = false
+
+	floor, state := car.Floor(), car.CurrentState()
+	evs := car.StepEvents()
+	at := s.now
+	if s.timed(car) {
+		at += s.stepTime(car, evs)
+	}
+	body, _ := stepBody(evs)
+	s.faulted[car] = body[0].Kind == StepFault && body[0].Fault != FaultMotorOverheat
+	s.Dispatcher.Events.PublishAt(at, evs)
+
+	if car.Floor() != floor {
+		s.push(at, EventCarArrival, car, nil)
+	}
+	if !state.doorOpen() && car.CurrentState().doorOpen() {
+		s.push(at, EventDoorOpen, car, nil)
+	}
+	if state.doorOpen() && !car.CurrentState().doorOpen() {
+		s.push(at, EventDoorClose, car, nil)
+	}
+	if state == StateOverloaded {
+		s.handleOverload(car)
+	}
+	s.wakeAt(car, at)
+
+	// A car just ran out of work: reposition the idle fleet.
+	if state != StateIdle && car.CurrentState() == StateIdle && !car.HasPendingRequests() {
+		for _, e := range s.Dispatcher.ParkIdle(s.Start + at) {
+			s.wakeAt(e, at)
+		}
+	}
+}
+
+// timed reports whether car's steps take their time from its Kinematics.
+func (s *Simulator) timed(car CarModel) bool {
+	return s.Timed && car.Kinematics().MaxSpeed > 0
+}
+
+// stepTime is how long the step that produced evs takes on car.
+//
+// Passing a floor takes FloorTime; the floor a run stops at takes the rest
+// of the run's TravelTime, so the whole run matches the S-curve profile.
+// Idle steps only pick a direction and take no time.
+func (s *Simulator) stepTime(car CarModel, evs []StepEvent) time.Duration {
+	k := car.Kinematics()
+	evs, _ = stepBody(evs)
+	switch evs[0].Kind {
+	case StepMoved:
+		s.run[car]++
+		var stopped, opened bool
+		for _, ev := range evs[1:] {
+			stopped = stopped || ev.Kind == StepStopped || ev.Kind == StepParked ||
+				ev.Kind == StepRecalled || ev.Kind == StepEmergencyStop
+			opened = opened || ev.Kind == StepDoorOpened || ev.Kind == StepRecalled || ev.Kind == StepEmergencyStop
+		}
+		if !stopped {
+			return k.FloorTime()
+		}
+		n := s.run[car]
+		s.run[car] = 0
+		d := k.TravelTime(n) - time.Duration(n-1)*k.FloorTime()
+		if opened {
+			s.transfers[car] = 0
+			d += k.DoorOpen
+		}
+		return d
+	case StepDoorHeld:
+		// Passengers counted since the door opened or last dwelled: a door
+		// reopened for a late arrival dwells again only for the newcomers.
+		d := k.DwellTime(s.transfers[car])
+		s.transfers[car] = 0
+		return d
+	case StepDoorClosed:
+		s.run[car] = 0
+		return k.DoorClose
+	case StepOverloadAlarm:
+		// One rider steps back off.
+		return k.DwellPerPassenger
+	case StepDoorReopened:
+		// The closing door runs back open; the new dwell follows.
+		return k.DoorOpen
+	case StepHoldOpen:
+		return k.Dwell
+	case StepNudging:
+		// A nudging door closes at reduced speed.
+		return k.DoorClose
+	case StepFault:
+		// The controller holds the car; an overheated motor cools meanwhile.
+		return k.Dwell
+	default:
+		s.run[car] = 0
+		return 0
+	}
+}
+
+// handleDoorOpen stamps the passengers the car just let out or took in.
+// Boarding and alighting themselves happen inside the car's openDoor.
+// Passengers evacuated in an emergency are set aside unstamped.
+func (s *Simulator) handleDoorOpen(car CarModel) {
+	riding := s.riding[car][:0]
+	var changing []*Passenger
+	for _, p := range s.riding[car] {
+		if p.State == PassengerEvacuated {
+			s.evacuated = append(s.evacuated, p)
+			continue
+		}
+		if p.State == PassengerArrived {
+			s.transfers[car]++
+			if s.Dispatcher.changesCars(p) {
+				changing = append(changing, p)
+				continue
+			}
+			p.AlightTime = s.now
+			s.done = append(s.done, p)
+			continue
+		}
+		riding = append(riding, p)
+	}
+	s.riding[car] = riding
+
+	waiting := s.waiting[car][:0]
+	for _, p := range s.waiting[car] {
+		if p.State == PassengerEvacuated {
+			s.evacuated = append(s.evacuated, p)
+			continue
+		}
+		if p.State == PassengerRiding {
+			if !s.secondLeg[p] {
+				p.BoardTime = s.now
+			}
+			s.riding[car] = append(s.riding[car], p)
+			s.transfers[car]++
+			continue
+		}
+		waiting = append(waiting, p)
+	}
+	s.waiting[car] = waiting
+	s.wake(car)
+
+	// Riders who reached their sky lobby set off on their second leg.
+	for _, p := range changing {
+		s.secondLeg[p] = true
+		s.assign(p, s.Dispatcher.continueTrip(p))
+	}
+}
+
+// handleOverload moves the riders an overloaded car put back off into its
+// waiting list, so their next boarding is stamped afresh.
+func (s *Simulator) handleOverload(car CarModel) {
+	riding := s.riding[car][:0]
+	for _, p := range s.riding[car] {
+		if p.State == PassengerWaiting {
+			s.waiting[car] = append(s.waiting[car], p)
+			continue
+		}
+		riding = append(riding, p)
+	}
+	s.riding[car] = riding
+}
+
+// Repair clears car carID's fault, puts it back into service and wakes it
+// if it still has work.
+func (s *Simulator) Repair(carID int) error {
+	if err := s.Dispatcher.Repair(carID); err != nil {
+		return err
+	}
+	car, _ := s.Dispatcher.car(carID)
+	s.wake(car)
+	return nil
+}
+
+// wake queues the car's next step if it has work and none is queued yet.
+func (s *Simulator) wake(car CarModel) { s.wakeAt(car, s.now) }
+
+// wakeAt queues the car's next step from t on. An untimed step runs
+// StepDuration after t; a timed step runs at t and takes its time afterwards.
+// A car parked in fire service or stopped by an emergency sleeps like an
+// idle one; the Simulator does not run emergency power, so a stranded car
+// stays where it is. Likewise a car held by a fault other than an overheated
+// motor sleeps until it is repaired (see Repair).
+func (s *Simulator) wakeAt(car CarModel, t time.Duration) {
+	if s.scheduled[car] {
+		return
+	}
+	if s.faulted[car] && car.Fault() != FaultNone {
+		return
+	}
+	switch car.CurrentState() {
+	case StateIdle, StateFireService, StateEmergencyStop, StateStranded:
+		if !car.HasPendingRequests() {
+			return
+		}
+	}
+	s.scheduled[car] = true
+	if !s.timed(car) {
+		t += s.StepDuration
+	}
+	s.push(t, EventCarStep, car, nil)
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+func TestSimulator_SinglePassenger_AlreadyAtFloor(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	s := NewSimulator(d, time.Second)
+
+	p := s.AddPassenger(0, 1, 5)
+	r := s.Run()
+
+	if len(r.Passengers) != 1 {
+		t.Fatalf("expected 1 completed trip, got %d", len(r.Passengers))
+	}
+	// Car is at floor 1: door opens at once, two door steps, four floors up.
+	if p.WaitTime() != 0 {
+		t.Errorf("expected wait 0s, got %v", p.WaitTime())
+	}
+	if p.RideTime() != 6*time.Second {
+		t.Errorf("expected ride 6s, got %v", p.RideTime())
+	}
+}
+
+func TestSimulator_WaitAndRideTimes(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	s := NewSimulator(d, time.Second)
+
+	p := s.AddPassenger(10*time.Second, 3, 1)
+	r := s.Run()
+
+	if r.Pending != 0 || len(r.Passengers) != 1 {
+		t.Fatalf("expected passenger served, got pending=%d done=%d", r.Pending, len(r.Passengers))
+	}
+	// Two floors up to reach 3, then two door steps and two floors down.
+	if p.BoardTime != 12*time.Second || p.WaitTime() != 2*time.Second {
+		t.Errorf("expected board at 12s (wait 2s), got board=%v wait=%v", p.BoardTime, p.WaitTime())
+	}
+	if p.RideTime() != 4*time.Second {
+		t.Errorf("expected ride 4s, got %v", p.RideTime())
+	}
+}
+
+func TestSimulator_EventSequence(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	s := NewSimulator(d, time.Second)
+
+	var kinds []EventKind
+	s.OnEvent = func(ev SimEvent) { kinds = append(kinds, ev.Kind) }
+
+	s.AddPassenger(0, 2, 3)
+	s.Run()
+
+	expected := []EventKind{
+		EventPassengerArrival,
+		EventCarArrival, EventDoorOpen, EventDoorClose, // pick up at 2
+		EventCarArrival, EventDoorOpen, EventDoorClose, // drop off at 3
+	}
+	if len(kinds) != len(expected) {
+		t.Fatalf("expected %v, got %v", expected, kinds)
+	}
+	for i := range expected {
+		if kinds[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, kinds)
+		}
+	}
+}
+
+func TestSimulator_IdleTimeIsSkipped(t *testing.T) {
+	d := NewDispatcher(2, 1, 10)
+	s := NewSimulator(d, time.Second)
+
+	s.AddPassenger(0, 1, 2)
+	s.AddPassenger(10*time.Hour, 1, 2)
+	r := s.Run()
+
+	if len(r.Passengers) != 2 {
+		t.Fatalf("expected 2 completed trips, got %d", len(r.Passengers))
+	}
+	// No step events are queued while every car is idle, so the ten idle
+	// hours cost nothing.
+	if r.Events > 30 {
+		t.Errorf("expected idle gap to be skipped, processed %d events", r.Events)
+	}
+	if r.Elapsed < 10*time.Hour {
+		t.Errorf("expected clock past 10h, got %v", r.Elapsed)
+	}
+}
+
+func TestSimulator_HoursOfTraffic_AllServed(t *testing.T) {
+	cars := []CarModel{
+		NewElevator(1, 1, 20),
+		NewBitmaskElevator(2, 1, 20),
+		NewBitsetElevator(3, 1, 20),
+	}
+	d := NewDispatcherWithCars(1, 20, cars)
+	s := NewSimulator(d, time.Second)
+
+	rng := rand.New(rand.NewPCG(1, 2))
+	n := 0
+	for at := time.Duration(0); at < 3*time.Hour; at += 30 * time.Second {
+		origin := 1 + rng.IntN(20)
+		dest := 1 + rng.IntN(20)
+		if s.AddPassenger(at, origin, dest) != nil {
+			n++
+		}
+	}
+
+	r := s.Run()
+
+	if len(r.Passengers) != n || r.Pending != 0 || r.Unserved != 0 {
+		t.Fatalf("expected %d trips served, got done=%d pending=%d unserved=%d",
+			n, len(r.Passengers), r.Pending, r.Unserved)
+	}
+	for _, p := range r.Passengers {
+		if p.WaitTime() < 0 || p.RideTime() <= 0 {
+			t.Fatalf("passenger %d has invalid times: wait=%v ride=%v", p.ID, p.WaitTime(), p.RideTime())
+		}
+	}
+	if r.AvgWait <= 0 || r.AvgRide <= 0 || r.MaxWait < r.AvgWait {
+		t.Errorf("unexpected stats: %+v", r)
+	}
+}
+
+func TestSimulator_RunUntil(t *testing.T) {
+	d := NewDispatcher(1, 1, 10)
+	s := NewSimulator(d, time.Second)
+
+	s.AddPassenger(0, 1, 10)
+	r := s.RunUntil(5 * time.Second)
+
+	if len(r.Passengers) != 0 || r.Pending != 1 {
+		t.Fatalf("expected passenger still riding at 5s, got done=%d pending=%d", len(r.Passengers), r.Pending)
+	}
+	if s.Now() != 5*time.Second {
+		t.Errorf("expected clock at 5s, got %v", s.Now())
+	}
+
+	r = s.Run()
+	if len(r.Passengers) != 1 {
+		t.Errorf("expected trip completed after Run, got %d", len(r.Passengers))
+	}
+}
+
+func TestSimulator_InvalidTrip(t *testing.T) {
+	s := NewSimulator(NewDispatcher(1, 1, 10), time.Second)
+
+	if s.AddPassenger(0, 3, 3) != nil {
+		t.Error("expected nil for origin == destination")
+	}
+	if s.AddPassenger(0, 0, 3) != nil {
+		t.Error("expected nil for out-of-range origin")
+	}
+}
+
+func TestSimulator_FaultedCarSleepsUntilRepaired(t *testing.T) {
+	for _, timed := range []bool{true, false} {
+		for _, f := range []Fault{FaultSensor, FaultStuck} {
+			d := NewDispatcher(1, 1, 10)
+			s := NewSimulator(d, time.Second)
+			s.Timed = timed
+			s.AddPassenger(0, 1, 8)
+			d.Elevators[0].InjectFault(f)
+
+			if r := s.RunUntil(10 * time.Second); len(r.Passengers) != 0 || r.Pending != 1 {
+				t.Fatalf("timed=%v %s: expected the passenger held by the fault, got done=%d pending=%d",
+					timed, f, len(r.Passengers), r.Pending)
+			}
+			if r := s.Run(); r.Pending != 1 {
+				t.Fatalf("timed=%v %s: expected Run to stop with the passenger held, got pending=%d", timed, f, r.Pending)
+			}
+
+			if err := s.Repair(1); err != nil {
+				t.Fatal(err)
+			}
+			if r := s.Run(); len(r.Passengers) != 1 {
+				t.Errorf("timed=%v %s: expected the trip completed after repair, got %d", timed, f, len(r.Passengers))
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// TrafficProfile is one of the standard elevator traffic patterns.
+type TrafficProfile int
+
+const (
+	TrafficUpPeak     TrafficProfile = iota // Morning: most trips start at the lobby going up
+	TrafficDownPeak                         // Evening: most trips end at the lobby
+	TrafficLunch                            // Two-way: to and from the lobby in equal share
+	TrafficInterFloor                       // Uniform trips between any two floors
+)
+
+func (p TrafficProfile) String() string {
+	switch p {
+	case TrafficUpPeak:
+		return "UpPeak"
+	case TrafficDownPeak:
+		return "DownPeak"
+	case TrafficLunch:
+		return "Lunch"
+	default:
+		return "InterFloor"
+	}
+}
+
+// trafficMix is the share of incoming (lobby → floor), outgoing
+// (floor → lobby) and inter-floor trips; the three add up to 1.
+type trafficMix struct {
+	incoming, outgoing, interFloor float64
+}
+
+var trafficMixes = map[TrafficProfile]trafficMix{
+	TrafficUpPeak:     {incoming: 0.85, outgoing: 0.05, interFloor: 0.10},
+	TrafficDownPeak:   {incoming: 0.05, outgoing: 0.85, interFloor: 0.10},
+	TrafficLunch:      {incoming: 0.45, outgoing: 0.45, interFloor: 0.10},
+	TrafficInterFloor: {incoming: 0, outgoing: 0, interFloor: 1},
+}
+
+// Arrival is one generated trip: a passenger showing up at Origin at time At.
+type Arrival struct {
+	At          time.Duration
+	Origin      int
+	Destination int
+}
+
+// HallRequest returns the hall call the arriving passenger places.
+func (a Arrival) HallRequest() Request {
+	dir := DirDown
+	if a.Destination > a.Origin {
+		dir = DirUp
+	}
+	return Request{Floor: a.Origin, Direction: dir, Type: HallCall}
+}
+
+// TrafficGenerator produces reproducible passenger arrivals for a profile.
+//
+// Arrivals follow a Poisson process: inter-arrival gaps are exponentially
+// distributed with mean 1/rate. The same seed always yields the same stream.
+type TrafficGenerator struct {
+	Profile  TrafficProfile
+	MinFloor int
+	MaxFloor int
+	Lobby    int
+
+	rng *rand.Rand
+}
+
+// NewTrafficGenerator creates a seeded generator with the lobby at minFloor.
+func NewTrafficGenerator(profile TrafficProfile, minFloor, maxFloor int, seed uint64) *TrafficGenerator {
+	return &TrafficGenerator{
+		Profile:  profile,
+		MinFloor: minFloor,
+		MaxFloor: maxFloor,
+		Lobby:    minFloor,
+		rng:      rand.New(rand.NewPCG(seed, seed^0x9e3779b97f4a7c15)),
+	}
+}
+
+// Generate returns the arrivals over [0, window) at an average of
+// perMinute passengers per minute, ordered by time.
+func (g *TrafficGenerator) Generate(window time.Duration, perMinute float64) []Arrival {
+	if perMinute <= 0 || g.MaxFloor <= g.MinFloor {
+		return nil
+	}
+	mean := float64(time.Minute) / perMinute
+
+	var arrivals []Arrival
+	at := time.Duration(g.rng.ExpFloat64() * mean)
+	for at < window {
+		origin, dest := g.trip()
+		arrivals = append(arrivals, Arrival{At: at, Origin: origin, Destination: dest})
+		at += time.Duration(g.rng.ExpFloat64() * mean)
+	}
+	return arrivals
+}
+
+// Requests returns the next n hall calls, ignoring arrival times.
+func (g *TrafficGenerator) Requests(n int) []Request {
+	reqs := make([]Request, n)
+	for i := range reqs {
+		origin, dest := g.trip()
+		reqs[i] = Arrival{Origin: origin, Destination: dest}.HallRequest()
+	}
+	return reqs
+}
+
+// trip draws an origin and destination according to the profile's mix.
+func (g *TrafficGenerator) trip() (origin, dest int) {
+	mix := trafficMixes[g.Profile]
+	x := g.rng.Float64()
+	switch {
+	case x < mix.incoming:
+		return g.Lobby, g.floorExcept(g.Lobby)
+	case x < mix.incoming+mix.outgoing:
+		return g.floorExcept(g.Lobby), g.Lobby
+	default:
+		origin = g.floor()
+		return origin, g.floorExcept(origin)
+	}
+}
+
+// floor returns a uniformly random floor.
+func (g *TrafficGenerator) floor() int {
+	return g.MinFloor + g.rng.IntN(g.MaxFloor-g.MinFloor+1)
+}
+
+// floorExcept returns a uniformly random floor other than skip.
+func (g *TrafficGenerator) floorExcept(skip int) int {
+	f := g.MinFloor + g.rng.IntN(g.MaxFloor-g.MinFloor)
+	if f >= skip {
+		f++
+	}
+	return f
+}
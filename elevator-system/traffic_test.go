@@ -0,0 +1,111 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrafficGenerator_Reproducible(t *testing.T) {
+	a := NewTrafficGenerator(TrafficLunch, 1, 20, 42).Generate(time.Hour, 10)
+	b := NewTrafficGenerator(TrafficLunch, 1, 20, 42).Generate(time.Hour, 10)
+	c := NewTrafficGenerator(TrafficLunch, 1, 20, 43).Generate(time.Hour, 10)
+
+	if len(a) != len(b) {
+		t.Fatalf("same seed produced different lengths: %d vs %d", len(a), len(b))
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("same seed diverged at %d: %+v vs %+v", i, a[i], b[i])
+		}
+	}
+	if len(a) == len(c) && a[0] == c[0] && a[len(a)-1] == c[len(c)-1] {
+		t.Error("different seeds produced the same stream")
+	}
+}
+
+func TestTrafficGenerator_ValidArrivals(t *testing.T) {
+	for _, profile := range []TrafficProfile{TrafficUpPeak, TrafficDownPeak, TrafficLunch, TrafficInterFloor} {
+		arrivals := NewTrafficGenerator(profile, 1, 20, 7).Generate(time.Hour, 20)
+
+		// 20/min over an hour ≈ 1200 arrivals.
+		if len(arrivals) < 1000 || len(arrivals) > 1400 {
+			t.Errorf("%s: expected ~1200 arrivals, got %d", profile, len(arrivals))
+		}
+		var last time.Duration
+		for _, a := range arrivals {
+			if a.At < last || a.At >= time.Hour {
+				t.Fatalf("%s: arrival time %v out of order or window", profile, a.At)
+			}
+			last = a.At
+			if a.Origin == a.Destination ||
+				a.Origin < 1 || a.Origin > 20 || a.Destination < 1 || a.Destination > 20 {
+				t.Fatalf("%s: invalid trip %+v", profile, a)
+			}
+		}
+	}
+}
+
+func TestTrafficGenerator_ProfileShape(t *testing.T) {
+	share := func(profile TrafficProfile) (fromLobby, toLobby float64) {
+		arrivals := NewTrafficGenerator(profile, 1, 20, 1).Generate(10*time.Hour, 10)
+		var from, to int
+		for _, a := range arrivals {
+			if a.Origin == 1 {
+				from++
+			}
+			if a.Destination == 1 {
+				to++
+			}
+		}
+		n := float64(len(arrivals))
+		return float64(from) / n, float64(to) / n
+	}
+
+	if from, _ := share(TrafficUpPeak); from < 0.8 {
+		t.Errorf("up-peak: expected most trips from the lobby, got %.2f", from)
+	}
+	if _, to := share(TrafficDownPeak); to < 0.8 {
+		t.Errorf("down-peak: expected most trips to the lobby, got %.2f", to)
+	}
+	if from, to := share(TrafficLunch); from < 0.35 || to < 0.35 {
+		t.Errorf("lunch: expected two-way lobby traffic, got from=%.2f to=%.2f", from, to)
+	}
+	if from, to := share(TrafficInterFloor); from > 0.15 || to > 0.15 {
+		t.Errorf("inter-floor: expected no lobby bias, got from=%.2f to=%.2f", from, to)
+	}
+}
+
+func TestTrafficGenerator_RequestsFeedDispatcher(t *testing.T) {
+	d := NewDispatcher(3, 1, 12)
+	reqs := NewTrafficGenerator(TrafficUpPeak, 1, 12, 3).Requests(30)
+
+	for i, r := range reqs {
+		if d.Dispatch(r) == nil {
+			t.Fatalf("request %d (%s) was not dispatched", i, r)
+		}
+		d.StepAll()
+	}
+	for range 500 {
+		if d.AllIdle() {
+			break
+		}
+		d.StepAll()
+	}
+	if !d.AllIdle() {
+		t.Errorf("fleet did not drain generated traffic:\n%s", d.Status())
+	}
+}
+
+func TestTrafficGenerator_DrivesSimulator(t *testing.T) {
+	for _, profile := range []TrafficProfile{TrafficUpPeak, TrafficDownPeak, TrafficLunch, TrafficInterFloor} {
+		arrivals := NewTrafficGenerator(profile, 1, 15, 11).Generate(30*time.Minute, 6)
+		s := NewSimulator(NewDispatcher(3, 1, 15), time.Second)
+		s.AddArrivals(arrivals)
+
+		r := s.Run()
+		if len(r.Passengers) != len(arrivals) || r.Pending != 0 {
+			t.Errorf("%s: expected %d trips served, got %d (pending %d)",
+				profile, len(arrivals), len(r.Passengers), r.Pending)
+		}
+	}
+}
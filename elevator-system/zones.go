@@ -0,0 +1,164 @@
+package main
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+)
+
+var ErrFloorNotServed = errors.New("floor not served by this car")
+
+// zone is the set of floors a car stops at. Tall buildings split cars into
+// banks (low-rise, high-rise, an express shuttle to a sky lobby) that run
+// through the floors they do not serve.
+type zone struct {
+	lo, hi int
+	served map[int]bool // nil: every floor in lo..hi
+}
+
+func newZone(minFloor, maxFloor int) zone {
+	return zone{lo: minFloor, hi: maxFloor}
+}
+
+// Serves reports whether the car stops at floor.
+func (z *zone) Serves(floor int) bool {
+	if floor < z.lo || floor > z.hi {
+		return false
+	}
+	return z.served == nil || z.served[floor]
+}
+
+// ServedFloors returns the floors the car stops at, lowest first.
+func (z *zone) ServedFloors() []int {
+	var floors []int
+	for f := z.lo; f <= z.hi; f++ {
+		if z.Serves(f) {
+			floors = append(floors, f)
+		}
+	}
+	return floors
+}
+
+// terminal reports whether floor is the lowest or highest floor the car
+// stops at.
+func (z *zone) terminal(floor int) bool {
+	floors := z.ServedFloors()
+	return len(floors) > 0 && (floor == floors[0] || floor == floors[len(floors)-1])
+}
+
+// SetServedFloors restricts the car to floors; with none it serves every
+// floor again. Stops already registered are kept, so set the zone before
+// the car takes calls. Returns ErrFloorNotServed, changing nothing, if a
+// floor is outside the car's range.
+func (z *zone) SetServedFloors(floors ...int) error {
+	if len(floors) == 0 {
+		z.served = nil
+		return nil
+	}
+	served := make(map[int]bool, len(floors))
+	for _, f := range floors {
+		if f < z.lo || f > z.hi {
+			return ErrFloorNotServed
+		}
+		served[f] = true
+	}
+	z.served = served
+	return nil
+}
+
+// WithSkyLobbies names the floors where a passenger may change cars when no
+// single car serves both ends of the trip.
+func WithSkyLobbies(floors ...int) DispatcherOption {
+	return func(d *Dispatcher) { d.SkyLobbies = floors }
+}
+
+// serving filters cars down to those that stop at every one of floors.
+func serving(cars []CarModel, floors ...int) []CarModel {
+	return slices.DeleteFunc(cars, func(e CarModel) bool {
+		return slices.ContainsFunc(floors, func(f int) bool { return !e.Serves(f) })
+	})
+}
+
+// transferFloor picks the sky lobby for a trip no single car in service
+// serves: among the lobbies with a car for each leg, the one that adds the
+// least travel. Returns false if there is none.
+func (d *Dispatcher) transferFloor(origin, destination int) (int, bool) {
+	cars := d.inService()
+	var lobbies []int
+	for _, l := range d.SkyLobbies {
+		if l != origin && l != destination &&
+			len(serving(slices.Clone(cars), origin, l)) > 0 &&
+			len(serving(slices.Clone(cars), l, destination)) > 0 {
+			lobbies = append(lobbies, l)
+		}
+	}
+	if len(lobbies) == 0 {
+		return 0, false
+	}
+	detour := func(l int) int { return abs(origin-l) + abs(l-destination) }
+	return slices.MinFunc(lobbies, func(a, b int) int { return cmp.Compare(detour(a), detour(b)) }), true
+}
+
+// planTransfer routes p through a sky lobby if no car in service serves both
+// its origin and destination: p.Destination becomes the lobby until the
+// first leg is done. Returns false if the trip cannot be served at all.
+func (d *Dispatcher) planTransfer(p *Passenger) bool {
+	if len(serving(d.inService(), p.Origin, p.Destination)) > 0 {
+		return true
+	}
+	lobby, ok := d.transferFloor(p.Origin, p.Destination)
+	if !ok {
+		return false
+	}
+	if d.legs == nil {
+		d.legs = make(map[*Passenger]int)
+	}
+	d.legs[p] = p.Destination
+	p.Destination = lobby
+	return true
+}
+
+// changesCars reports whether p is on the first leg of a transfer trip.
+func (d *Dispatcher) changesCars(p *Passenger) bool {
+	_, ok := d.legs[p]
+	return ok
+}
+
+// routable reports whether some car in service, or two of them changing at a
+// sky lobby, can take a trip from origin to destination.
+func (d *Dispatcher) routable(origin, destination int) bool {
+	if len(serving(d.inService(), origin, destination)) > 0 {
+		return true
+	}
+	_, ok := d.transferFloor(origin, destination)
+	return ok
+}
+
+// continueTrip dispatches the second leg of p's trip once it has stepped out
+// at the sky lobby. Returns the car for that leg, or nil if p is not
+// transferring there or no car can take it yet; p then waits at the lobby,
+// still changing cars, and tickTransfers tries again on the next tick.
+func (d *Dispatcher) continueTrip(p *Passenger) CarModel {
+	dest, ok := d.legs[p]
+	if !ok || p.State != PassengerArrived || !d.routable(p.Destination, dest) {
+		return nil
+	}
+	delete(d.legs, p)
+	p.Origin, p.Destination = p.Destination, dest
+	return d.DispatchPassenger(p)
+}
+
+// tickTransfers sends the passengers waiting at their sky lobby on their
+// second leg, in passenger order.
+func (d *Dispatcher) tickTransfers() {
+	var arrived []*Passenger
+	for p := range d.legs {
+		if p.State == PassengerArrived {
+			arrived = append(arrived, p)
+		}
+	}
+	slices.SortFunc(arrived, func(a, b *Passenger) int { return cmp.Compare(a.ID, b.ID) })
+	for _, p := range arrived {
+		d.continueTrip(p)
+	}
+}
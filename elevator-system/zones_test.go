@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestZone_RejectsUnservedFloors(t *testing.T) {
+	for name, newCar := range carConstructors {
+		t.Run(name, func(t *testing.T) {
+			car := newCar(1, 1, 20)
+			if err := car.SetServedFloors(1, 10, 11, 12, 21); !errors.Is(err, ErrFloorNotServed) {
+				t.Errorf("expected ErrFloorNotServed for floor 21, got %v", err)
+			}
+			if got := car.ServedFloors(); len(got) != 20 {
+				t.Errorf("failed SetServedFloors must change nothing, got %v", got)
+			}
+
+			if err := car.SetServedFloors(1, 10, 11, 12); err != nil {
+				t.Fatal(err)
+			}
+			if err := car.AddRequest(Request{Floor: 5, Type: CabCall}); !errors.Is(err, ErrFloorNotServed) {
+				t.Errorf("expected ErrFloorNotServed for floor 5, got %v", err)
+			}
+			if err := car.AddRequest(Request{Floor: 25, Type: CabCall}); !errors.Is(err, ErrFloorNotServed) {
+				t.Errorf("expected ErrFloorNotServed out of range, got %v", err)
+			}
+			if car.HasPendingRequests() {
+				t.Error("rejected requests must not be latched")
+			}
+			if car.Park(5) {
+				t.Error("expected Park to refuse an unserved floor")
+			}
+
+			car.SetMaintenance(true)
+			if err := car.AddRequest(Request{Floor: 10, Type: CabCall}); !errors.Is(err, ErrInMaintenance) {
+				t.Errorf("expected ErrInMaintenance, got %v", err)
+			}
+			car.SetMaintenance(false)
+			if err := car.AddRequest(Request{Floor: 11, Type: CabCall}); err != nil {
+				t.Errorf("expected served floor accepted, got %v", err)
+			}
+
+			if err := car.SetServedFloors(); err != nil || !car.Serves(5) {
+				t.Errorf("expected every floor served again, got %v", err)
+			}
+		})
+	}
+}
+
+func TestZone_ExpressRunsThroughUnservedFloors(t *testing.T) {
+	e := NewElevator(1, 1, 30)
+	e.SetServedFloors(1, 20, 21, 22)
+	e.AddRequest(Request{Floor: 22, Type: CabCall})
+	e.AddRequest(Request{Floor: 20, Type: CabCall})
+
+	stops := runUntilIdle(e, 100)
+	if !intSliceEqual(stops, []int{20, 22}) {
+		t.Errorf("expected [20 22], got %v", stops)
+	}
+}
+
+// newBankedDispatcher builds a 20-floor building with a low-rise car serving
+// 1–11 and a high-rise car serving 11–20, changing at the sky lobby on 11.
+func newBankedDispatcher(opts ...DispatcherOption) *Dispatcher {
+	d := NewDispatcher(2, 1, 20, opts...)
+	low, high := d.Elevators[0], d.Elevators[1]
+	low.SetServedFloors(1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11)
+	high.SetServedFloors(11, 12, 13, 14, 15, 16, 17, 18, 19, 20)
+	return d
+}
+
+func TestDispatcher_OnlyServingCarsChosen(t *testing.T) {
+	d := newBankedDispatcher()
+	elevatorAt(d, 1).CurrentFloor = 20
+
+	// The low-rise car is nearer but does not serve 15.
+	if car := d.Dispatch(Request{Floor: 15, Direction: DirDown, Type: HallCall}); car == nil || car.CarID() != 2 {
+		t.Errorf("expected high-rise car 2, got %v", car)
+	}
+	if car := d.DispatchPassenger(NewPassenger(1, 12, 19, 0)); car == nil || car.CarID() != 2 {
+		t.Errorf("expected high-rise car 2, got %v", car)
+	}
+}
+
+func TestDispatcher_NoRouteWithoutSkyLobby(t *testing.T) {
+	d := newBankedDispatcher()
+	p := NewPassenger(1, 3, 18, 0)
+	if car := d.DispatchPassenger(p); car != nil {
+		t.Errorf("expected no car without a sky lobby, got %d", car.CarID())
+	}
+	if p.Destination != 18 {
+		t.Errorf("expected trip untouched, destination %d", p.Destination)
+	}
+	if _, err := d.DispatchDestination(3, 18); !errors.Is(err, ErrNoCarAvailable) {
+		t.Errorf("expected ErrNoCarAvailable, got %v", err)
+	}
+}
+
+func TestDispatcher_TransfersAtSkyLobby(t *testing.T) {
+	d := newBankedDispatcher(WithSkyLobbies(11))
+	p := NewPassenger(1, 3, 18, 0)
+
+	if car := d.DispatchPassenger(p); car == nil || car.CarID() != 1 {
+		t.Fatalf("expected first leg on low-rise car 1, got %v", car)
+	}
+	if p.Destination != 11 {
+		t.Errorf("expected first leg to the sky lobby, got %d", p.Destination)
+	}
+
+	for range 200 {
+		d.StepAll()
+		if p.State == PassengerArrived && !d.changesCars(p) {
+			break
+		}
+	}
+	if p.State != PassengerArrived || p.Origin != 11 || p.Destination != 18 {
+		t.Errorf("expected arrival at 18 via 11, got %s %d→%d", p.State, p.Origin, p.Destination)
+	}
+	if d.Elevators[1].Floor() != 18 {
+		t.Errorf("expected the high-rise car to deliver, it is at %d", d.Elevators[1].Floor())
+	}
+}
+
+func TestDispatcher_SecondLegWaitsForACar(t *testing.T) {
+	d := newBankedDispatcher(WithSkyLobbies(11))
+	p := NewPassenger(1, 3, 18, 0)
+	d.DispatchPassenger(p)
+	d.SetMaintenance(2, true)
+
+	for range 60 {
+		d.StepAll()
+	}
+	if !d.changesCars(p) || p.Origin != 3 || p.Destination != 11 {
+		t.Fatalf("expected the passenger waiting at the sky lobby, got %s %d→%d (changing %v)",
+			p.State, p.Origin, p.Destination, d.changesCars(p))
+	}
+
+	d.SetMaintenance(2, false)
+	for range 60 {
+		d.StepAll()
+	}
+	if p.State != PassengerArrived || p.Destination != 18 || d.Elevators[1].Floor() != 18 {
+		t.Errorf("expected the high-rise car to deliver to 18, got %s %d→%d with the car at %d",
+			p.State, p.Origin, p.Destination, d.Elevators[1].Floor())
+	}
+}
+
+func TestSimulator_TransferKeepsFirstBoardTime(t *testing.T) {
+	d := newBankedDispatcher(WithSkyLobbies(11))
+	s := NewSimulator(d, time.Second)
+	p := s.AddPassenger(0, 18, 3)
+
+	r := s.Run()
+	if len(r.Passengers) != 1 || r.Unserved != 0 {
+		t.Fatalf("expected one completed trip, got %d (unserved %d)", len(r.Passengers), r.Unserved)
+	}
+	// The high-rise car starts at 1, outside its zone: it runs up empty to 18.
+	if p.Destination != 3 || p.BoardTime != 17*time.Second {
+		t.Errorf("expected boarding at 18 after 17s, got %v (destination %d)", p.BoardTime, p.Destination)
+	}
+	if p.AlightTime <= p.BoardTime+15*time.Second {
+		t.Errorf("expected a ride through the sky lobby, got %v", p.RideTime())
+	}
+}